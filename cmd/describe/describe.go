@@ -13,17 +13,67 @@ var flags []cli.Flag = []cli.Flag{
 	},
 }
 
+var serviceFlags []cli.Flag = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "format",
+		Value: "json",
+		Usage: "output a formatted description, e.g. json, yaml, openapi, jsonschema or proto",
+	},
+}
+
+var endpointFlags []cli.Flag = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "format",
+		Value: "text",
+		Usage: "output a formatted description, e.g. text, json, yaml or jsonschema",
+	},
+}
+
 func init() {
 	mcli.Register(&cli.Command{
 		Name:  "describe",
 		Usage: "Describe a resource",
 		Subcommands: []*cli.Command{
+			{
+				Name:    "diff",
+				Aliases: []string{"d"},
+				Usage:   "Compare the endpoints of two service versions, e.g. " + mcli.App().Name + " describe diff helloworld --from v1 --to v2",
+				Action:  Diff,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "old version of the service",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "new version of the service",
+					},
+					&cli.StringFlag{
+						Name:  "snapshot",
+						Usage: "read the old version from a snapshot taken with registry export",
+					},
+				},
+			},
+			{
+				Name:    "endpoint",
+				Aliases: []string{"e"},
+				Usage:   "Describe a service endpoint resource, e.g. " + mcli.App().Name + " describe endpoint helloworld Helloworld.Call",
+				Action:  Endpoint,
+				Flags:   endpointFlags,
+			},
+			{
+				Name:    "node",
+				Aliases: []string{"n"},
+				Usage:   "Describe a service node resource, e.g. " + mcli.App().Name + " describe node helloworld helloworld-9660f06a-d608-43d9-9f44-e264ff63c554",
+				Action:  Node,
+				Flags:   flags,
+			},
 			{
 				Name:    "service",
 				Aliases: []string{"s"},
 				Usage:   "Describe a service resource, e.g. " + mcli.App().Name + " describe service helloworld",
 				Action:  Service,
-				Flags:   flags,
+				Flags:   serviceFlags,
 			},
 		},
 	})
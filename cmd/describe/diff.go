@@ -0,0 +1,95 @@
+package describe
+
+import (
+	"fmt"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/go-micro/cli/snapshot"
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/registry"
+)
+
+// Diff compares the endpoints of two versions of a service and classifies
+// every change as breaking or compatible. The old version is read from a
+// snapshot when the snapshot flag is passed, otherwise both versions are read
+// from the registry. Exits with an error when breaking changes are found.
+func Diff(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	name := ctx.Args().First()
+
+	r := *mcli.DefaultOptions().Registry
+	live, err := r.GetService(name)
+	if err != nil && err != registry.ErrNotFound {
+		return err
+	}
+
+	old := live
+	if path := ctx.String("snapshot"); len(path) > 0 {
+		s, err := snapshot.Read(path)
+		if err != nil {
+			return err
+		}
+		old = s.Get(name)
+	}
+
+	from, err := pickVersion(name, old, ctx.String("from"), "from")
+	if err != nil {
+		return err
+	}
+	to, err := pickVersion(name, live, ctx.String("to"), "to")
+	if err != nil {
+		return err
+	}
+
+	changes := snapshot.DiffEndpoints(from, to)
+	if len(changes) == 0 {
+		fmt.Printf("no changes between %s %s and %s\n", name, from.Version, to.Version)
+		return nil
+	}
+
+	var breaking int
+	for _, c := range changes {
+		class := "compatible"
+		if c.Breaking() {
+			class = "breaking"
+			breaking++
+		}
+		fmt.Printf("%-10s %s\n", class, c)
+	}
+	fmt.Printf("%d breaking, %d compatible changes between %s %s and %s\n",
+		breaking, len(changes)-breaking, name, from.Version, to.Version)
+
+	if breaking > 0 {
+		return fmt.Errorf("breaking changes found")
+	}
+
+	return nil
+}
+
+// pickVersion returns the requested version of a service. If no version is
+// requested, the service must have a single version.
+func pickVersion(name string, srvs []*registry.Service, version, flag string) (*registry.Service, error) {
+	if len(srvs) == 0 {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+
+	if len(version) == 0 {
+		if len(srvs) > 1 {
+			var versions []string
+			for _, srv := range srvs {
+				versions = append(versions, srv.Version)
+			}
+			return nil, fmt.Errorf("service %s has versions %v, pass --%s to pick one", name, versions, flag)
+		}
+		return srvs[0], nil
+	}
+
+	for _, srv := range srvs {
+		if srv.Version == version {
+			return srv, nil
+		}
+	}
+	return nil, fmt.Errorf("version %s of service %s not found", version, name)
+}
@@ -0,0 +1,138 @@
+package describe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/registry"
+	"gopkg.in/yaml.v2"
+)
+
+// Endpoint fetches a service from the registry and prints the request and
+// response types of one of its endpoints as an indented type tree, or in
+// JSON, YAML or JSON Schema, depending on the format flag passed. Exits on
+// error.
+func Endpoint(ctx *cli.Context) error {
+	args := ctx.Args().Slice()
+	if len(args) < 2 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	format := ctx.String("format")
+	if format != "text" && format != "json" && format != "yaml" && format != "jsonschema" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	r := *mcli.DefaultOptions().Registry
+	srvs, err := r.GetService(args[0])
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, srv := range srvs {
+		for _, ep := range srv.Endpoints {
+			if ep.Name != args[1] {
+				continue
+			}
+			found = true
+
+			var b []byte
+			var err error
+			switch format {
+			case "text":
+				fmt.Printf("%s %s\n", srv.Name, srv.Version)
+				printEndpoint(os.Stdout, ep)
+				continue
+			case "json":
+				b, err = json.MarshalIndent(ep, "", "  ")
+			case "yaml":
+				b, err = yaml.Marshal(ep)
+			case "jsonschema":
+				b, err = json.MarshalIndent(endpointSchema(ep), "", "  ")
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+		}
+	}
+	if !found {
+		return fmt.Errorf("endpoint %s of service %s not found", args[1], args[0])
+	}
+
+	return nil
+}
+
+// endpointSchema returns the JSON Schemas of the endpoint request and
+// response.
+func endpointSchema(ep *registry.Endpoint) map[string]*schema {
+	req := jsonSchema(ep.Request)
+	req.Schema = schemaDraft
+	rsp := jsonSchema(ep.Response)
+	rsp.Schema = schemaDraft
+
+	return map[string]*schema{
+		"request":  req,
+		"response": rsp,
+	}
+}
+
+// printEndpoint writes the endpoint metadata and its request and response
+// type trees to w.
+func printEndpoint(w io.Writer, ep *registry.Endpoint) {
+	fmt.Fprintf(w, "endpoint %s\n", ep.Name)
+
+	var keys []string
+	for k := range ep.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", k, ep.Metadata[k])
+	}
+
+	printValue(w, "request", ep.Request, 1)
+	printValue(w, "response", ep.Response, 1)
+}
+
+// printValue writes a value and its fields to w as an indented tree, e.g.
+//
+//	request CallRequest {
+//	  name string
+//	  tags repeated string
+//	}
+func printValue(w io.Writer, name string, v *registry.Value, depth int) {
+	indent := strings.Repeat("  ", depth)
+	if v == nil {
+		fmt.Fprintf(w, "%s%s <none>\n", indent, name)
+		return
+	}
+
+	typ := elemType(v)
+	switch typ {
+	case "":
+		typ = "<unknown>"
+	case "[]uint8", "[]byte":
+		typ = "bytes"
+	}
+	if repeated(v) {
+		typ = "repeated " + typ
+	}
+
+	if len(v.Values) == 0 {
+		fmt.Fprintf(w, "%s%s %s\n", indent, name, typ)
+		return
+	}
+
+	fmt.Fprintf(w, "%s%s %s {\n", indent, name, typ)
+	for _, f := range v.Values {
+		printValue(w, f.Name, f, depth+1)
+	}
+	fmt.Fprintf(w, "%s}\n", indent)
+}
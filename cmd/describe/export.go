@@ -0,0 +1,309 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-micro.dev/v4/registry"
+)
+
+// openAPIDoc is an OpenAPI 3 document describing a service.
+type openAPIDoc struct {
+	OpenAPI    string                        `json:"openapi"`
+	Info       openAPIInfo                   `json:"info"`
+	Paths      map[string]map[string]*opItem `json:"paths"`
+	Components openAPIComponents             `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*schema `json:"schemas"`
+}
+
+type opItem struct {
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Description string               `json:"description,omitempty"`
+	RequestBody *opBody              `json:"requestBody,omitempty"`
+	Responses   map[string]*opResult `json:"responses"`
+}
+
+type opBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type opResult struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+// schemaDoc is a JSON Schema document holding the request and response types
+// of all endpoints of a service.
+type schemaDoc struct {
+	Schema      string             `json:"$schema"`
+	Title       string             `json:"title"`
+	Definitions map[string]*schema `json:"definitions"`
+}
+
+// splitEndpoint splits an endpoint name such as Helloworld.Call into its
+// handler and method.
+func splitEndpoint(name string) (string, string) {
+	if i := strings.Index(name, "."); i > 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, name
+}
+
+// endpointPath returns the HTTP path under which the micro API gateway
+// serves the endpoint, e.g. /helloworld/Helloworld/Call.
+func endpointPath(service, endpoint string) string {
+	handler, method := splitEndpoint(endpoint)
+	return "/" + service + "/" + handler + "/" + method
+}
+
+// typeName returns the name used to reference a request or response type.
+func typeName(v *registry.Value, fallback string) string {
+	if v == nil || len(v.Type) == 0 || len(v.Values) == 0 {
+		return fallback
+	}
+	return v.Type
+}
+
+// openAPI converts a service into an OpenAPI 3 document with a POST
+// operation per endpoint.
+func openAPI(srv *registry.Service) *openAPIDoc {
+	doc := &openAPIDoc{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:   srv.Name,
+			Version: srv.Version,
+		},
+		Paths:      make(map[string]map[string]*opItem),
+		Components: openAPIComponents{Schemas: make(map[string]*schema)},
+	}
+
+	ref := func(v *registry.Value, fallback string) *schema {
+		name := typeName(v, fallback)
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			doc.Components.Schemas[name] = jsonSchema(v)
+		}
+		return &schema{Ref: "#/components/schemas/" + name}
+	}
+
+	for _, ep := range srv.Endpoints {
+		handler, method := splitEndpoint(ep.Name)
+		op := &opItem{
+			OperationID: ep.Name,
+			Tags:        []string{handler},
+			RequestBody: &opBody{
+				Required: true,
+				Content: map[string]*mediaType{
+					"application/json": {Schema: ref(ep.Request, method+"Request")},
+				},
+			},
+			Responses: map[string]*opResult{
+				"200": {
+					Description: "OK",
+					Content: map[string]*mediaType{
+						"application/json": {Schema: ref(ep.Response, method+"Response")},
+					},
+				},
+				"default": {
+					Description: "Error",
+				},
+			},
+		}
+		if ep.Metadata["stream"] == "true" {
+			op.Description = "Streaming endpoint, served over a websocket by the gateway."
+		}
+
+		doc.Paths[endpointPath(srv.Name, ep.Name)] = map[string]*opItem{"post": op}
+	}
+
+	return doc
+}
+
+// serviceSchema converts the request and response types of all endpoints of
+// a service into JSON Schema definitions.
+func serviceSchema(srv *registry.Service) *schemaDoc {
+	doc := &schemaDoc{
+		Schema:      schemaDraft,
+		Title:       srv.Name,
+		Definitions: make(map[string]*schema),
+	}
+
+	for _, ep := range srv.Endpoints {
+		_, method := splitEndpoint(ep.Name)
+		for name, v := range map[string]*registry.Value{
+			typeName(ep.Request, method+"Request"):   ep.Request,
+			typeName(ep.Response, method+"Response"): ep.Response,
+		} {
+			if _, ok := doc.Definitions[name]; !ok {
+				doc.Definitions[name] = jsonSchema(v)
+			}
+		}
+	}
+
+	return doc
+}
+
+// protoScalar returns the protobuf type of a Go scalar type name, or an empty
+// string for non-scalar types.
+func protoScalar(typ string) string {
+	switch typ {
+	case "string", "bool", "int32", "int64", "uint32", "uint64":
+		return typ
+	case "int":
+		return "int64"
+	case "uint":
+		return "uint64"
+	case "int8", "int16":
+		return "int32"
+	case "uint8", "uint16":
+		return "uint32"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	case "[]uint8", "[]byte":
+		return "bytes"
+	}
+	return ""
+}
+
+// protoFile reconstructs a proto definition of a service. Field numbers are
+// not registered, so they are assigned in declaration order.
+func protoFile(srv *registry.Service) string {
+	messages := make(map[string]*registry.Value)
+	var order []string
+	structs := false
+
+	// message registers the message types of v and returns its proto type
+	var message func(v *registry.Value, fallback string) string
+	message = func(v *registry.Value, fallback string) string {
+		if v != nil {
+			if typ := protoScalar(elemType(v)); len(typ) > 0 {
+				return typ
+			}
+			if len(v.Type) == 0 {
+				structs = true
+				return "google.protobuf.Struct"
+			}
+			// the elements of a list of unnamed types, e.g. []map[string]string
+			// or [][]string, have no name to register a message by, nor is it
+			// known whether they're maps or lists
+			if len(elemType(v)) == 0 {
+				structs = true
+				return "google.protobuf.Value"
+			}
+		}
+
+		name := fallback
+		if v != nil && (len(v.Values) > 0 || len(fallback) == 0) {
+			name = elemType(v)
+		}
+		if _, ok := messages[name]; !ok {
+			messages[name] = v
+			order = append(order, name)
+			if v != nil {
+				for _, f := range v.Values {
+					message(f, "")
+				}
+			}
+		}
+		return name
+	}
+
+	handlers := make(map[string][]string)
+	var names []string
+	for _, ep := range srv.Endpoints {
+		handler, method := splitEndpoint(ep.Name)
+		stream := ep.Metadata["stream"] == "true"
+
+		// bidirectional stream handlers receive the stream in place of the
+		// request, which leaves the context as the registered request type
+		reqStream := stream && ep.Request != nil && ep.Request.Type == "Context"
+		req := ep.Request
+		if reqStream {
+			req = nil
+		}
+		rsp := ep.Response
+		if stream {
+			rsp = nil
+		}
+
+		rpc := fmt.Sprintf("rpc %s(%s%s) returns (%s%s) {}",
+			method,
+			prefix(reqStream, "stream "),
+			message(req, method+"Request"),
+			prefix(stream, "stream "),
+			message(rsp, method+"Response"),
+		)
+		if _, ok := handlers[handler]; !ok {
+			names = append(names, handler)
+		}
+		handlers[handler] = append(handlers[handler], rpc)
+	}
+
+	pkg := strings.NewReplacer("-", "_", "/", "_").Replace(srv.Name)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Reconstructed from the %s registry entry, version %s.\n", srv.Name, srv.Version)
+	fmt.Fprintln(&b, "// Field numbers are assigned in declaration order and may not match the")
+	fmt.Fprintln(&b, "// original definition.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, `syntax = "proto3";`)
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "package %s;\n", pkg)
+	if structs {
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, `import "google/protobuf/struct.proto";`)
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprintf(&b, "option go_package = \"./proto;%s\";\n", strings.ReplaceAll(pkg, ".", "_"))
+
+	sort.Strings(names)
+	for _, handler := range names {
+		fmt.Fprintln(&b)
+		fmt.Fprintf(&b, "service %s {\n", handler)
+		for _, rpc := range handlers[handler] {
+			fmt.Fprintf(&b, "\t%s\n", rpc)
+		}
+		fmt.Fprintln(&b, "}")
+	}
+
+	for _, name := range order {
+		v := messages[name]
+		fmt.Fprintln(&b)
+		if v == nil || len(v.Values) == 0 {
+			fmt.Fprintln(&b, "// The fields of this message are not registered.")
+			fmt.Fprintf(&b, "message %s {}\n", name)
+			continue
+		}
+		fmt.Fprintf(&b, "message %s {\n", name)
+		for i, f := range v.Values {
+			fmt.Fprintf(&b, "\t%s%s %s = %d;\n", prefix(repeated(f), "repeated "), message(f, ""), f.Name, i+1)
+		}
+		fmt.Fprintln(&b, "}")
+	}
+
+	return b.String()
+}
+
+func prefix(ok bool, s string) string {
+	if ok {
+		return s
+	}
+	return ""
+}
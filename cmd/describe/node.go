@@ -0,0 +1,66 @@
+package describe
+
+import (
+	"encoding/json"
+	"fmt"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/registry"
+	"gopkg.in/yaml.v2"
+)
+
+// instance is a single node of a service.
+type instance struct {
+	Service       string `json:"service"`
+	Version       string `json:"version"`
+	registry.Node `yaml:",inline"`
+}
+
+// Node fetches a service from the registry and prints a single node of it in
+// either JSON or YAML, depending on the format flag passed. Exits on error.
+func Node(ctx *cli.Context) error {
+	args := ctx.Args().Slice()
+	if len(args) < 2 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	if ctx.String("format") != "json" && ctx.String("format") != "yaml" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	r := *mcli.DefaultOptions().Registry
+	srvs, err := r.GetService(args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, srv := range srvs {
+		for _, node := range srv.Nodes {
+			if node.Id != args[1] {
+				continue
+			}
+
+			n := instance{
+				Service: srv.Name,
+				Version: srv.Version,
+				Node:    *node,
+			}
+
+			var b []byte
+			var err error
+			if ctx.String("format") == "json" {
+				b, err = json.MarshalIndent(n, "", "  ")
+			} else if ctx.String("format") == "yaml" {
+				b, err = yaml.Marshal(n)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("node %s of service %s not found", args[1], args[0])
+}
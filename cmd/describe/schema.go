@@ -0,0 +1,87 @@
+package describe
+
+import (
+	"strings"
+
+	"go-micro.dev/v4/registry"
+)
+
+// schema is a JSON Schema describing a registry value.
+type schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	Schema     string             `json:"$schema,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Properties map[string]*schema `json:"properties,omitempty"`
+	Items      *schema            `json:"items,omitempty"`
+}
+
+const schemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// repeated reports whether the value is a list. Byte slices are reported as
+// scalars, as they are encoded as strings.
+func repeated(v *registry.Value) bool {
+	return strings.HasPrefix(v.Type, "[]") && v.Type != "[]uint8" && v.Type != "[]byte"
+}
+
+// elemType returns the type of the value, or the type of its elements if the
+// value is a list.
+func elemType(v *registry.Value) string {
+	if repeated(v) {
+		return strings.TrimPrefix(v.Type, "[]")
+	}
+	return v.Type
+}
+
+// scalar returns the JSON Schema type and format of a Go scalar type name. An
+// empty type is returned for non-scalar types.
+func scalar(typ string) (string, string) {
+	switch typ {
+	case "string":
+		return "string", ""
+	case "bool":
+		return "boolean", ""
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32":
+		return "integer", "int32"
+	case "int64", "uint64":
+		return "integer", "int64"
+	case "float32":
+		return "number", "float"
+	case "float64":
+		return "number", "double"
+	case "[]uint8", "[]byte":
+		return "string", "byte"
+	}
+	return "", ""
+}
+
+// jsonSchema converts a registry value to a JSON Schema.
+func jsonSchema(v *registry.Value) *schema {
+	if v == nil {
+		return &schema{Type: "object"}
+	}
+
+	if repeated(v) {
+		item := *v
+		item.Type = elemType(v)
+		return &schema{Type: "array", Items: jsonSchema(&item)}
+	}
+
+	if typ, format := scalar(v.Type); len(typ) > 0 {
+		return &schema{Type: typ, Format: format}
+	}
+
+	if len(v.Values) == 0 {
+		// maps, enums and values nested too deep to be extracted carry no
+		// type information, so any value is accepted
+		return &schema{Title: v.Type}
+	}
+
+	s := &schema{Title: v.Type, Type: "object"}
+	s.Properties = make(map[string]*schema)
+	for _, f := range v.Values {
+		s.Properties[f.Name] = jsonSchema(f)
+	}
+	return s
+}
@@ -10,13 +10,17 @@ import (
 )
 
 // Service fetches information for a service from the registry and prints it in
-// either JSON or YAML, depending on the format flag passed. Exits on error.
+// either JSON or YAML, depending on the format flag passed. The openapi,
+// jsonschema and proto formats print a specification derived from the
+// registered endpoints instead. Exits on error.
 func Service(ctx *cli.Context) error {
 	args := ctx.Args().Slice()
 	if len(args) < 1 {
 		return cli.ShowSubcommandHelp(ctx)
 	}
-	if ctx.String("format") != "json" && ctx.String("format") != "yaml" {
+	switch ctx.String("format") {
+	case "json", "yaml", "openapi", "jsonschema", "proto":
+	default:
 		return cli.ShowSubcommandHelp(ctx)
 	}
 
@@ -32,10 +36,17 @@ func Service(ctx *cli.Context) error {
 	for _, srv := range srvs {
 		var b []byte
 		var err error
-		if ctx.String("format") == "json" {
+		switch ctx.String("format") {
+		case "json":
 			b, err = json.MarshalIndent(srv, "", "  ")
-		} else if ctx.String("format") == "yaml" {
+		case "yaml":
 			b, err = yaml.Marshal(srv)
+		case "openapi":
+			b, err = json.MarshalIndent(openAPI(srv), "", "  ")
+		case "jsonschema":
+			b, err = json.MarshalIndent(serviceSchema(srv), "", "  ")
+		case "proto":
+			b = []byte(protoFile(srv))
 		}
 		if err != nil {
 			return err
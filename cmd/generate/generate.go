@@ -12,6 +12,85 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+// flags override the options recorded in the project manifest. Only flags
+// that are passed are applied.
+var flags []cli.Flag = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "vendor",
+		Usage: "Service vendor, e.g. github.com/auditemarlow/",
+	},
+	&cli.BoolFlag{
+		Name:  "jaeger",
+		Usage: "Enable Jaeger tracer integration",
+	},
+	&cli.BoolFlag{
+		Name:  "skaffold",
+		Usage: "Enable Skaffold integration",
+	},
+	&cli.BoolFlag{
+		Name:  "tilt",
+		Usage: "Enable Tilt integration",
+	},
+	&cli.BoolFlag{
+		Name:  "health",
+		Usage: "Enable the gRPC Health service",
+	},
+	&cli.BoolFlag{
+		Name:  "kustomize",
+		Usage: "Enable kubernetes resource files in a kustomize structure",
+	},
+	&cli.BoolFlag{
+		Name:  "sqlc",
+		Usage: "Enable sqlc integration",
+	},
+	&cli.BoolFlag{
+		Name:  "grpc",
+		Usage: "Use gRPC as default server and client",
+	},
+	&cli.BoolFlag{
+		Name:  "buildkit",
+		Usage: "Use BuildKit features in Dockerfile",
+	},
+	&cli.BoolFlag{
+		Name:  "tern",
+		Usage: "Enable tern integration",
+	},
+	&cli.BoolFlag{
+		Name:  "advanced",
+		Usage: "Enable advanced features in main.go server file",
+	},
+	&cli.BoolFlag{
+		Name:  "privaterepo",
+		Usage: "Build from private repositories in Dockerfile (add ssh-agent)",
+	},
+	&cli.StringFlag{
+		Name:  "namespace",
+		Usage: "Default namespace for kubernetes resources",
+	},
+	&cli.StringFlag{
+		Name:  "postgresaddress",
+		Usage: "Default postgres address for kubernetes resources",
+	},
+	&cli.StringFlag{
+		Name:    "template-dir",
+		Usage:   "Directory of a template pack overriding the built-in templates",
+		EnvVars: []string{"MICRO_TEMPLATE_DIR"},
+	},
+	&cli.StringFlag{
+		Name:  "conflict",
+		Usage: "What to do with existing files that changed; skip, overwrite, prompt, or new to write <file>.new",
+		Value: generator.ConflictSkip,
+	},
+	&cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "List the files that would be created or overwritten without writing them",
+	},
+	&cli.BoolFlag{
+		Name:  "diff",
+		Usage: "Print a unified diff of the changes to the files without writing them",
+	},
+}
+
 func init() {
 	mcli.Register(&cli.Command{
 		Name:  "generate",
@@ -21,16 +100,19 @@ func init() {
 				Name:   "kubernetes",
 				Usage:  "Generate Kubernetes resource template files",
 				Action: Kubernetes,
+				Flags:  flags,
 			},
 			{
 				Name:   "skaffold",
 				Usage:  "Generate Skaffold template files",
 				Action: Skaffold,
+				Flags:  flags,
 			},
 			{
 				Name:   "sqlc",
 				Usage:  "Generate sqlc resources",
 				Action: Sqlc,
+				Flags:  flags,
 			},
 		},
 	})
@@ -39,23 +121,11 @@ func init() {
 // Kubernetes generates Kubernetes resource template files in the current
 // working directory. Exits on error.
 func Kubernetes(ctx *cli.Context) error {
-	service, err := getService()
+	g, err := newGenerator(ctx)
 	if err != nil {
 		return err
 	}
 
-	vendor, err := getServiceVendor(service)
-	if err != nil {
-		return err
-	}
-
-	g := generator.New(
-		generator.Service(service),
-		generator.Vendor(vendor),
-		generator.Directory("."),
-		generator.Client(strings.HasSuffix(service, "-client")),
-	)
-
 	files := []generator.File{
 		{Path: "plugins.go", Template: tmpl.Plugins},
 		{Path: "resources/clusterrole.yaml", Template: tmpl.KubernetesClusterRole},
@@ -64,32 +134,21 @@ func Kubernetes(ctx *cli.Context) error {
 		{Path: "resources/rolebinding.yaml", Template: tmpl.KubernetesRoleBinding},
 	}
 
-	g.Generate(files)
+	if err := g.Generate(files); err != nil {
+		return err
+	}
 
-	return nil
+	return generator.WriteManifest(g.Options())
 }
 
 // Skaffold generates Skaffold template files in the current working directory.
 // Exits on error.
 func Skaffold(ctx *cli.Context) error {
-	service, err := getService()
+	g, err := newGenerator(ctx, generator.Skaffold(true))
 	if err != nil {
 		return err
 	}
 
-	vendor, err := getServiceVendor(service)
-	if err != nil {
-		return err
-	}
-
-	g := generator.New(
-		generator.Service(service),
-		generator.Vendor(vendor),
-		generator.Directory("."),
-		generator.Client(strings.HasSuffix(service, "-client")),
-		generator.Skaffold(true),
-	)
-
 	files := []generator.File{
 		{Path: ".dockerignore", Template: tmpl.DockerIgnore},
 		{Path: "go.mod", Template: tmpl.Module},
@@ -105,7 +164,13 @@ func Skaffold(ctx *cli.Context) error {
 		return err
 	}
 
-	fmt.Println("skaffold project template files generated")
+	if err := generator.WriteManifest(g.Options()); err != nil {
+		return err
+	}
+
+	if opts := g.Options(); !opts.DryRun && !opts.Diff {
+		fmt.Println("skaffold project template files generated")
+	}
 
 	return nil
 }
@@ -113,46 +178,128 @@ func Skaffold(ctx *cli.Context) error {
 // Sqlc generates sqlc files in the current working directory.
 // Exits on error.
 func Sqlc(ctx *cli.Context) error {
-	service, err := getService()
+	g, err := newGenerator(ctx, generator.Sqlc(true))
 	if err != nil {
 		return err
 	}
 
-	vendor, err := getServiceVendor(service)
-	if err != nil {
+	files := []generator.File{
+		{Path: "postgres/queries/example.sql", Template: tmpl.QueryExample},
+		{Path: "postgres/migrations/", Template: ""},
+		{Path: "postgres/postgres.go", Template: tmpl.Postgres},
+		{Path: "postgres/sqlc.yaml", Template: tmpl.Sqlc},
+	}
+
+	if err := g.Generate(files); err != nil {
 		return err
 	}
 
-	g := generator.New(
-		generator.Service(service),
-		generator.Vendor(vendor),
-		generator.Directory("."),
-		generator.Client(strings.HasSuffix(service, "-client")),
-		generator.Sqlc(true),
-	)
+	if err := generator.WriteManifest(g.Options()); err != nil {
+		return err
+	}
 
-	files := []generator.File{
-		{Path: "postgres/queries/example.sql", Template: tmpl.QueryExample},
-		{Path: "postgres/migrations/", Template: ""},
+	if opts := g.Options(); !opts.DryRun && !opts.Diff {
+		fmt.Println("Sqlc project template files generated")
 	}
 
-	path := "postgres/postgres.go"
-	if _, err := os.Stat("./" + path); err != nil {
-		files = append(files, generator.File{Path: path, Template: tmpl.Postgres})
+	return nil
+}
+
+// newGenerator returns a generator for the project in the current working
+// directory. Its options are read from the project manifest, or derived from
+// the directory name and go.mod if there is none, and overridden by the flags
+// passed and then by opts. Existing files that changed are resolved with the
+// conflict strategy passed.
+func newGenerator(ctx *cli.Context, opts ...generator.Option) (generator.Generator, error) {
+	conflict := ctx.String("conflict")
+	switch conflict {
+	case generator.ConflictSkip, generator.ConflictOverwrite, generator.ConflictPrompt, generator.ConflictNew:
+	default:
+		return nil, fmt.Errorf("unknown conflict strategy %q, must be one of skip, overwrite, prompt or new", conflict)
 	}
 
-	path = "postgres/sqlc.yaml"
-	if _, err := os.Stat("./" + path); err != nil {
-		files = append(files, generator.File{Path: path, Template: tmpl.Sqlc})
+	base, err := generator.ReadManifest(".")
+	if os.IsNotExist(err) {
+		base, err = projectOptions()
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	if err := g.Generate(files); err != nil {
-		return err
+	options := []generator.Option{generator.WithOptions(base), generator.Conflict(conflict)}
+	options = append(options, flagOptions(ctx)...)
+	options = append(options, opts...)
+
+	return generator.New(options...), nil
+}
+
+// projectOptions derives the options of a project without a manifest from
+// the current working directory.
+func projectOptions() (generator.Options, error) {
+	service, err := getService()
+	if err != nil {
+		return generator.Options{}, err
+	}
+
+	vendor, err := getServiceVendor(service)
+	if err != nil {
+		return generator.Options{}, err
 	}
 
-	fmt.Println("Sqlc project template files generated")
+	return generator.Options{
+		Service:         service,
+		Vendor:          vendor,
+		Directory:       ".",
+		Client:          strings.HasSuffix(service, "-client"),
+		Namespace:       "default",
+		PostgresAddress: "postgres.database.svc",
+	}, nil
+}
+
+// flagOptions returns the options of the flags passed.
+func flagOptions(ctx *cli.Context) []generator.Option {
+	var opts []generator.Option
 
-	return nil
+	strs := []struct {
+		flag   string
+		option func(string) generator.Option
+	}{
+		{"vendor", generator.Vendor},
+		{"namespace", generator.Namespace},
+		{"postgresaddress", generator.PostgresAddress},
+		{"template-dir", generator.TemplateDir},
+	}
+	for _, s := range strs {
+		if ctx.IsSet(s.flag) {
+			opts = append(opts, s.option(ctx.String(s.flag)))
+		}
+	}
+
+	bools := []struct {
+		flag   string
+		option func(bool) generator.Option
+	}{
+		{"jaeger", generator.Jaeger},
+		{"skaffold", generator.Skaffold},
+		{"tilt", generator.Tilt},
+		{"health", generator.Health},
+		{"kustomize", generator.Kustomize},
+		{"sqlc", generator.Sqlc},
+		{"grpc", generator.GRPC},
+		{"buildkit", generator.Buildkit},
+		{"tern", generator.Tern},
+		{"advanced", generator.Advanced},
+		{"privaterepo", generator.PrivateRepo},
+		{"dry-run", generator.DryRun},
+		{"diff", generator.Diff},
+	}
+	for _, b := range bools {
+		if ctx.IsSet(b.flag) {
+			opts = append(opts, b.option(ctx.Bool(b.flag)))
+		}
+	}
+
+	return opts
 }
 
 func getService() (string, error) {
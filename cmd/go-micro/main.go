@@ -9,9 +9,11 @@ import (
 	_ "github.com/go-micro/cli/cmd/describe"
 	_ "github.com/go-micro/cli/cmd/generate"
 	_ "github.com/go-micro/cli/cmd/new"
+	_ "github.com/go-micro/cli/cmd/registry"
 	_ "github.com/go-micro/cli/cmd/run"
 	_ "github.com/go-micro/cli/cmd/services"
 	_ "github.com/go-micro/cli/cmd/stream"
+	_ "github.com/go-micro/cli/cmd/top"
 
 	// plugins
 	_ "github.com/go-micro/plugins/v4/registry/kubernetes"
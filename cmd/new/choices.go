@@ -0,0 +1,132 @@
+package new
+
+import (
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	mcli "github.com/go-micro/cli/cmd"
+)
+
+// Defaults of the string flags.
+const (
+	defaultNamespace       = "default"
+	defaultPostgresAddress = "postgres.database.svc"
+)
+
+// implications lists the flags that enable an option besides its own flag.
+var implications = map[string][]string{
+	"jaeger":    {"complete"},
+	"tilt":      {"complete"},
+	"health":    {"complete"},
+	"kustomize": {"complete"},
+	"sqlc":      {"complete"},
+	"grpc":      {"health", "complete"},
+	"buildkit":  {"privaterepo", "complete"},
+	"tern":      {"complete"},
+	"advanced":  {"complete"},
+}
+
+// choices are the flags a project is created with.
+type choices struct {
+	// Type is the project type, either client, function or service.
+	Type string
+	// Module is the module path of the project, e.g.
+	// github.com/auditemarlow/helloworld.
+	Module string
+	// Flags are the boolean flags that were passed.
+	Flags map[string]bool
+	// Namespace is the default namespace for kubernetes resources.
+	Namespace string
+	// PostgresAddress is the default postgres address for kubernetes
+	// resources.
+	PostgresAddress string
+	// TemplateDir is the directory of the template pack.
+	TemplateDir string
+}
+
+func choicesFromFlags(ctx *cli.Context, pt, module string) choices {
+	c := choices{
+		Type:            pt,
+		Module:          module,
+		Flags:           make(map[string]bool),
+		Namespace:       ctx.String("namespace"),
+		PostgresAddress: ctx.String("postgresaddress"),
+		TemplateDir:     ctx.String("template-dir"),
+	}
+	for _, name := range boolFlags() {
+		c.Flags[name] = ctx.Bool(name)
+	}
+	return c
+}
+
+// Enabled reports whether an option is enabled, by its own flag or by a flag
+// implying it.
+func (c choices) Enabled(name string) bool {
+	return c.Flags[name] || len(c.ImpliedBy(name)) > 0
+}
+
+// ImpliedBy returns the flag enabling an option that wasn't passed itself.
+func (c choices) ImpliedBy(name string) string {
+	if c.Flags[name] {
+		return ""
+	}
+	for _, flag := range implications[name] {
+		if c.Flags[flag] {
+			return flag
+		}
+	}
+	return ""
+}
+
+// CommandLine returns the command that creates the project without prompting.
+func (c choices) CommandLine() string {
+	args := []string{mcli.App().Name, "new", c.Type}
+	for _, name := range boolFlags() {
+		if c.Flags[name] {
+			args = append(args, "--"+name)
+		}
+	}
+	if c.Namespace != defaultNamespace {
+		args = append(args, "--namespace="+c.Namespace)
+	}
+	if c.PostgresAddress != defaultPostgresAddress {
+		args = append(args, "--postgresaddress="+c.PostgresAddress)
+	}
+	if len(c.TemplateDir) > 0 {
+		args = append(args, "--template-dir="+c.TemplateDir)
+	}
+	return strings.Join(append(args, c.Module), " ")
+}
+
+// boolFlags returns the names of the boolean flags of the new command, in
+// the order they're defined.
+func boolFlags() []string {
+	var names []string
+	for _, f := range flags {
+		if b, ok := f.(*cli.BoolFlag); ok {
+			names = append(names, b.Name)
+		}
+	}
+	return names
+}
+
+// usage returns the usage of a flag of the new command.
+func usage(name string) string {
+	for _, f := range flags {
+		if d, ok := f.(cli.DocGenerationFlag); ok && f.Names()[0] == name {
+			return d.GetUsage()
+		}
+	}
+	return ""
+}
+
+// isTerminal reports whether stdin is an interactive terminal.
+func isTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
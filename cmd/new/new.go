@@ -16,6 +16,25 @@ import (
 	tmpl "github.com/go-micro/cli/generator/template"
 )
 
+// templateDirFlag sets the template pack used.
+var templateDirFlag = &cli.StringFlag{
+	Name:    "template-dir",
+	Usage:   "Directory of a template pack overriding or adding to the built-in templates",
+	EnvVars: []string{"MICRO_TEMPLATE_DIR"},
+}
+
+// previewFlags preview the files of a project instead of creating them.
+var previewFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "List the files that would be created without writing them",
+	},
+	&cli.BoolFlag{
+		Name:  "diff",
+		Usage: "Print a unified diff of the files that would be created without writing them",
+	},
+}
+
 var flags []cli.Flag = []cli.Flag{
 	&cli.BoolFlag{
 		Name:  "jaeger",
@@ -79,36 +98,49 @@ var flags []cli.Flag = []cli.Flag{
 		Name:  "complete",
 		Usage: "Complete will set the following flags to true; jaeger, health, grpc, sqlc, tern, kustomize, tilt, advanced",
 	},
+	templateDirFlag,
 }
 
 // NewCommand returns a new new cli command.
 func init() {
 	mcli.Register(&cli.Command{
-		Name:  "new",
-		Usage: "Create a project template",
+		Name:   "new",
+		Usage:  "Create a project template, interactively when run without arguments in a terminal",
+		Action: New,
+		Flags:  []cli.Flag{templateDirFlag},
 		Subcommands: []*cli.Command{
 			{
 				Name:   "client",
 				Usage:  "Create a client template, e.g. " + mcli.App().Name + " new client [github.com/auditemarlow/]helloworld",
 				Action: Client,
-				Flags:  flags,
+				Flags:  append(flags, previewFlags...),
 			},
 			{
 				Name:   "function",
 				Usage:  "Create a function template, e.g. " + mcli.App().Name + " new function [github.com/auditemarlow/]helloworld",
 				Action: Function,
-				Flags:  flags,
+				Flags:  append(flags, previewFlags...),
 			},
 			{
 				Name:   "service",
 				Usage:  "Create a service template, e.g. " + mcli.App().Name + " new service [github.com/auditemarlow/]helloworld",
 				Action: Service,
-				Flags:  flags,
+				Flags:  append(flags, previewFlags...),
 			},
 		},
 	})
 }
 
+// New creates a new project template with the choices made in an
+// interactive wizard. Exits on error.
+func New(ctx *cli.Context) error {
+	if ctx.Args().Len() > 0 || !isTerminal() {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	return wizard("", ctx.String("template-dir"))
+}
+
+// Client creates a new client project template. Exits on error.
 func Client(ctx *cli.Context) error {
 	return createProject(ctx, "client")
 }
@@ -126,11 +158,34 @@ func Service(ctx *cli.Context) error {
 func createProject(ctx *cli.Context, pt string) error {
 	arg := ctx.Args().First()
 	if len(arg) == 0 {
+		if !flagsSet(ctx) && isTerminal() {
+			return wizard(pt, ctx.String("template-dir"))
+		}
 		return cli.ShowSubcommandHelp(ctx)
 	}
 
+	c := choicesFromFlags(ctx, pt, arg)
+	return create(c, generator.DryRun(ctx.Bool("dry-run")), generator.Diff(ctx.Bool("diff")))
+}
+
+// flagsSet reports whether a flag other than the template pack, which may be
+// set in a wizard too, was passed.
+func flagsSet(ctx *cli.Context) bool {
+	for _, f := range append(flags, previewFlags...) {
+		name := f.Names()[0]
+		if name != "template-dir" && ctx.IsSet(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// create creates a project template with the options implied by the choices,
+// and further generator options.
+func create(c choices, extra ...generator.Option) error {
+	pt := c.Type
 	client := pt == "client"
-	name, vendor := getNameAndVendor(arg)
+	name, vendor := getNameAndVendor(c.Module)
 
 	dir := name
 	if client {
@@ -148,25 +203,27 @@ func createProject(ctx *cli.Context, pt string) error {
 
 	fmt.Printf("creating %s %s\n", pt, name)
 
-	g := generator.New(
+	options := []generator.Option{
 		generator.Service(name),
 		generator.Vendor(vendor),
 		generator.Directory(dir),
+		generator.TemplateDir(c.TemplateDir),
 		generator.Client(client),
-		generator.Jaeger(ctx.Bool("jaeger") || ctx.Bool("complete")),
-		generator.Skaffold(ctx.Bool("skaffold")),
-		generator.Tilt(ctx.Bool("tilt") || ctx.Bool("complete")),
-		generator.Health(ctx.Bool("health") || ctx.Bool("complete")),
-		generator.Kustomize(ctx.Bool("kustomize") || ctx.Bool("complete")),
-		generator.Sqlc(ctx.Bool("sqlc") || ctx.Bool("complete")),
-		generator.GRPC(ctx.Bool("grpc") || ctx.Bool("health") || ctx.Bool("complete")),
-		generator.Buildkit(ctx.Bool("buildkit") || ctx.Bool("privaterepo") || ctx.Bool("complete")),
-		generator.Tern(ctx.Bool("tern") || ctx.Bool("complete")),
-		generator.Advanced(ctx.Bool("advanced") || ctx.Bool("complete")),
-		generator.PrivateRepo(ctx.Bool("privaterepo")),
-		generator.Namespace(ctx.String("namespace")),
-		generator.PostgresAddress(ctx.String("postgresaddress")),
-	)
+		generator.Jaeger(c.Enabled("jaeger")),
+		generator.Skaffold(c.Enabled("skaffold")),
+		generator.Tilt(c.Enabled("tilt")),
+		generator.Health(c.Enabled("health")),
+		generator.Kustomize(c.Enabled("kustomize")),
+		generator.Sqlc(c.Enabled("sqlc")),
+		generator.GRPC(c.Enabled("grpc")),
+		generator.Buildkit(c.Enabled("buildkit")),
+		generator.Tern(c.Enabled("tern")),
+		generator.Advanced(c.Enabled("advanced")),
+		generator.PrivateRepo(c.Enabled("privaterepo")),
+		generator.Namespace(c.Namespace),
+		generator.PostgresAddress(c.PostgresAddress),
+	}
+	g := generator.New(append(options, extra...)...)
 
 	files := []generator.File{
 		{Path: ".dockerignore", Template: tmpl.DockerIgnore},
@@ -220,7 +277,7 @@ func createProject(ctx *cli.Context, pt string) error {
 		}...)
 	}
 
-	if (ctx.Bool("kubernetes") || opts.Skaffold || opts.Tilt) && !opts.Kustomize {
+	if (c.Enabled("kubernetes") || opts.Skaffold || opts.Tilt) && !opts.Kustomize {
 		files = append(files, []generator.File{
 			{Path: "plugins.go", Template: tmpl.Plugins},
 			{Path: "resources/clusterrole.yaml", Template: tmpl.KubernetesClusterRole},
@@ -259,6 +316,14 @@ func createProject(ctx *cli.Context, pt string) error {
 		return err
 	}
 
+	if err := generator.WriteManifest(opts); err != nil {
+		return err
+	}
+
+	if opts.DryRun || opts.Diff {
+		return nil
+	}
+
 	var comments []string
 	if client {
 		comments = clientComments(name, dir)
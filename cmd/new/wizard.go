@@ -0,0 +1,176 @@
+package new
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// prompter asks questions on stdout and reads the answers from stdin.
+type prompter struct {
+	in *bufio.Reader
+}
+
+// Ask asks a question and returns the answer, or def if the answer is empty.
+func (p *prompter) Ask(question, def string) (string, error) {
+	if len(def) > 0 {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && len(line) == 0 {
+		fmt.Println()
+		return "", fmt.Errorf("no answer to %q", question)
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	answer := strings.TrimSpace(line)
+	if len(answer) == 0 {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// Choose asks a question until the answer is one of the options.
+func (p *prompter) Choose(question string, options []string, def string) (string, error) {
+	for {
+		answer, err := p.Ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, ", ")), def)
+		if err != nil {
+			return "", err
+		}
+		for _, o := range options {
+			if answer == o {
+				return answer, nil
+			}
+		}
+		fmt.Printf("please answer one of %s\n", strings.Join(options, ", "))
+	}
+}
+
+// Confirm asks a yes or no question.
+func (p *prompter) Confirm(question string, def bool) (bool, error) {
+	hint := " [y/N]"
+	if def {
+		hint = " [Y/n]"
+	}
+	for {
+		answer, err := p.Ask(question+hint, "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Println("please answer yes or no")
+	}
+}
+
+// wizard prompts for the project type, module path, features and namespace,
+// and creates the project. The project type isn't asked for when pt is set.
+func wizard(pt, templateDir string) error {
+	p := &prompter{in: bufio.NewReader(os.Stdin)}
+	c := choices{
+		Type:            pt,
+		Flags:           make(map[string]bool),
+		Namespace:       defaultNamespace,
+		PostgresAddress: defaultPostgresAddress,
+		TemplateDir:     templateDir,
+	}
+
+	var err error
+	if len(c.Type) == 0 {
+		c.Type, err = p.Choose("Project type", []string{"service", "function", "client"}, "service")
+		if err != nil {
+			return err
+		}
+	}
+
+	for len(c.Module) == 0 {
+		c.Module, err = p.Ask("Module path, e.g. github.com/auditemarlow/helloworld", "")
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(c.Module, "/") {
+			fmt.Println("must provide a relative path as service name")
+			c.Module = ""
+		}
+	}
+
+	fmt.Println("\nFeatures, press enter to keep the default:")
+	c.Flags["complete"], err = p.Confirm("complete - "+usage("complete")+"?", false)
+	if err != nil {
+		return err
+	}
+	for _, name := range boolFlags() {
+		if name == "complete" || c.Enabled(name) {
+			continue
+		}
+		c.Flags[name], err = p.Confirm(fmt.Sprintf("%s - %s?", name, usage(name)), false)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.Enabled("kubernetes") || c.Enabled("kustomize") || c.Enabled("skaffold") || c.Enabled("tilt") {
+		c.Namespace, err = p.Ask("Kubernetes namespace", c.Namespace)
+		if err != nil {
+			return err
+		}
+	}
+	if c.Enabled("sqlc") || c.Enabled("tern") {
+		c.PostgresAddress, err = p.Ask("Postgres address", c.PostgresAddress)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\n%s %s will be created with:\n", c.Type, c.Module)
+	var enabled bool
+	for _, name := range boolFlags() {
+		if name == "complete" || !c.Enabled(name) {
+			continue
+		}
+		enabled = true
+		if by := c.ImpliedBy(name); len(by) > 0 {
+			fmt.Printf("  %s (implied by %s)\n", name, by)
+		} else {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+	if !enabled {
+		fmt.Println("  no additional features")
+	}
+	if c.Enabled("kubernetes") || c.Enabled("kustomize") || c.Enabled("skaffold") || c.Enabled("tilt") {
+		fmt.Printf("  namespace %s\n", c.Namespace)
+	}
+	if c.Enabled("sqlc") || c.Enabled("tern") {
+		fmt.Printf("  postgres address %s\n", c.PostgresAddress)
+	}
+	fmt.Println()
+
+	ok, err := p.Confirm("Create it?", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	if err := create(c); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nto create it again without prompting, run:\n\n%s\n", c.CommandLine())
+	return nil
+}
@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"fmt"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/go-micro/cli/snapshot"
+	"github.com/urfave/cli/v2"
+)
+
+// Diff compares two snapshots and prints the added, removed and modified
+// services, versions, nodes and endpoints. When a single snapshot is passed,
+// it is compared with the live registry. Exits on error.
+func Diff(ctx *cli.Context) error {
+	args := ctx.Args().Slice()
+	if len(args) < 1 || len(args) > 2 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	a, err := snapshot.Read(args[0])
+	if err != nil {
+		return err
+	}
+
+	var b *snapshot.Snapshot
+	if len(args) == 2 {
+		b, err = snapshot.Read(args[1])
+	} else {
+		b, err = snapshot.Take(*mcli.DefaultOptions().Registry)
+	}
+	if err != nil {
+		return err
+	}
+
+	changes := snapshot.Diff(a, b)
+	if len(changes) == 0 {
+		fmt.Println("no differences")
+		return nil
+	}
+
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package registry
+
+import (
+	"os"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/go-micro/cli/snapshot"
+	"github.com/urfave/cli/v2"
+)
+
+// Export takes a snapshot of all services in the registry and prints it in
+// either YAML or JSON, depending on the format flag passed. Exits on error.
+func Export(ctx *cli.Context) error {
+	if ctx.String("format") != "json" && ctx.String("format") != "yaml" {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	r := *mcli.DefaultOptions().Registry
+	s, err := snapshot.Take(r)
+	if err != nil {
+		return err
+	}
+
+	return s.Write(os.Stdout, ctx.String("format"))
+}
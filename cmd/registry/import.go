@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/go-micro/cli/snapshot"
+	"github.com/urfave/cli/v2"
+	mregistry "go-micro.dev/v4/registry"
+)
+
+// Import registers every service of a snapshot and keeps the registrations
+// alive until interrupted, after which they are deregistered. Exits on error.
+func Import(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	s, err := snapshot.Read(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	if len(s.Services) == 0 {
+		return fmt.Errorf("snapshot %s holds no services", ctx.Args().First())
+	}
+
+	r := *mcli.DefaultOptions().Registry
+	return keepalive(r, s.Services, ctx.Duration("ttl"), ctx.Duration("interval"))
+}
+
+// keepalive registers the services and renews their registration on every
+// interval until interrupted, after which they are deregistered.
+func keepalive(r mregistry.Registry, srvs []*mregistry.Service, ttl, interval time.Duration) error {
+	if interval <= 0 || interval >= ttl {
+		return fmt.Errorf("invalid interval %v, must be greater than 0 and less than the ttl of %v", interval, ttl)
+	}
+
+	register := func() error {
+		for _, srv := range srvs {
+			if err := r.Register(srv, mregistry.RegisterTTL(ttl)); err != nil {
+				return fmt.Errorf("failed to register %s %s: %v", srv.Name, srv.Version, err)
+			}
+		}
+		return nil
+	}
+
+	if err := register(); err != nil {
+		return err
+	}
+	for _, srv := range srvs {
+		fmt.Printf("registered %s %s with %d nodes in %s registry\n", srv.Name, srv.Version, len(srv.Nodes), r.String())
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			if err := register(); err != nil {
+				fmt.Println(err)
+			}
+		case <-sig:
+			for _, srv := range srvs {
+				if err := r.Deregister(srv); err != nil {
+					fmt.Printf("failed to deregister %s %s: %v\n", srv.Name, srv.Version, err)
+					continue
+				}
+				fmt.Printf("deregistered %s %s\n", srv.Name, srv.Version)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/go-micro/cli/snapshot"
+	"github.com/urfave/cli/v2"
+	mregistry "go-micro.dev/v4/registry"
+)
+
+// requiredMetadata is the node metadata every go-micro server registers.
+var requiredMetadata = []string{"transport", "protocol", "broker"}
+
+// problem is a single issue found by the linter.
+type problem struct {
+	Error   bool
+	Service string
+	Message string
+}
+
+func (p problem) String() string {
+	severity := "warning"
+	if p.Error {
+		severity = "error"
+	}
+	return fmt.Sprintf("%-7s %s: %s", severity, p.Service, p.Message)
+}
+
+// Lint inspects every service in the registry, or in a snapshot when one is
+// passed, and reports duplicate node ids, versions running concurrently,
+// endpoints that differ between versions and nodes with missing metadata.
+// Incompatible endpoint changes are errors, compatible ones warnings.
+// Exits with an error when errors are found, or warnings if strict.
+func Lint(ctx *cli.Context) error {
+	if ctx.Args().Len() > 1 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	var s *snapshot.Snapshot
+	var err error
+	if ctx.Args().Len() == 1 {
+		s, err = snapshot.Read(ctx.Args().First())
+	} else {
+		s, err = snapshot.Take(*mcli.DefaultOptions().Registry)
+	}
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, srv := range s.Services {
+		if len(names) == 0 || names[len(names)-1] != srv.Name {
+			names = append(names, srv.Name)
+		}
+	}
+
+	var problems []problem
+	for _, name := range names {
+		problems = append(problems, lintService(name, s.Get(name))...)
+	}
+
+	var errs, warnings int
+	for _, p := range problems {
+		fmt.Println(p)
+		if p.Error {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	fmt.Printf("%d services, %d errors, %d warnings\n", len(names), errs, warnings)
+
+	if errs > 0 || (ctx.Bool("strict") && warnings > 0) {
+		return fmt.Errorf("lint failed")
+	}
+
+	return nil
+}
+
+// lintService checks all registered versions of a service.
+func lintService(name string, srvs []*mregistry.Service) []problem {
+	srvs = sortVersions(srvs)
+
+	var problems []problem
+	report := func(isErr bool, format string, a ...interface{}) {
+		problems = append(problems, problem{
+			Error:   isErr,
+			Service: name,
+			Message: fmt.Sprintf(format, a...),
+		})
+	}
+
+	// duplicate node ids, within or across versions
+	seen := make(map[string][]string)
+	var ids []string
+	for _, srv := range srvs {
+		for _, node := range srv.Nodes {
+			if _, ok := seen[node.Id]; !ok {
+				ids = append(ids, node.Id)
+			}
+			seen[node.Id] = append(seen[node.Id], srv.Version)
+		}
+	}
+	for _, id := range ids {
+		if len(seen[id]) > 1 {
+			report(true, "node id %s registered %d times, in versions %s", id, len(seen[id]), strings.Join(seen[id], ", "))
+		}
+	}
+
+	// versions running concurrently
+	var versions []string
+	for _, srv := range srvs {
+		if len(srv.Nodes) > 0 {
+			versions = append(versions, srv.Version)
+		}
+	}
+	if len(versions) > 1 {
+		report(false, "%d versions running concurrently: %s", len(versions), strings.Join(versions, ", "))
+	}
+
+	// endpoints that differ between versions. The registry merges the nodes
+	// of a version into one service with a single set of endpoints, so nodes
+	// of the same version, e.g. latest during a rolling deploy, can't be told
+	// apart.
+	endpoints := make(map[string][]*mregistry.Service)
+	var epNames []string
+	for _, srv := range srvs {
+		for _, ep := range srv.Endpoints {
+			if _, ok := endpoints[ep.Name]; !ok {
+				epNames = append(epNames, ep.Name)
+			}
+			endpoints[ep.Name] = append(endpoints[ep.Name], srv)
+		}
+	}
+	sort.Strings(epNames)
+	for _, ep := range epNames {
+		owners := endpoints[ep]
+		if len(owners) < len(srvs) {
+			var missing []string
+			for _, srv := range srvs {
+				if !hasEndpoint(srv, ep) {
+					missing = append(missing, srv.Version)
+				}
+			}
+			report(false, "endpoint %s missing in versions %s", ep, strings.Join(missing, ", "))
+		}
+
+		first := endpoint(owners[0], ep)
+		for _, srv := range owners[1:] {
+			other := endpoint(srv, ep)
+			for _, part := range []struct {
+				name string
+				a, b *mregistry.Value
+			}{
+				{"request", first.Request, other.Request},
+				{"response", first.Response, other.Response},
+			} {
+				for _, c := range snapshot.DiffValue(part.name, part.a, part.b) {
+					report(c.Breaking(), "endpoint %s %s between versions %s and %s", ep, describeField(c), owners[0].Version, srv.Version)
+				}
+			}
+		}
+	}
+
+	// nodes with missing metadata
+	for _, srv := range srvs {
+		for _, node := range srv.Nodes {
+			var missing []string
+			for _, key := range requiredMetadata {
+				if len(node.Metadata[key]) == 0 {
+					missing = append(missing, key)
+				}
+			}
+			if len(missing) > 0 {
+				report(false, "node %s missing metadata %s", node.Id, strings.Join(missing, ", "))
+			}
+		}
+	}
+
+	return problems
+}
+
+// sortVersions returns the versions of a service from oldest to newest, so
+// an endpoint is compared against the oldest version that has it. Versions
+// are ordered semantically, e.g. v9 before v10, and versions that aren't
+// numbers, e.g. latest, keep their order after the numbered ones.
+func sortVersions(srvs []*mregistry.Service) []*mregistry.Service {
+	sorted := append([]*mregistry.Service{}, srvs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return versionLess(sorted[i].Version, sorted[j].Version)
+	})
+	return sorted
+}
+
+// versionLess reports whether version a precedes b. Versions are compared by
+// their dot separated numbers, with an optional v prefix. A pre-release, e.g.
+// 1.0.0-rc1, precedes its release.
+func versionLess(a, b string) bool {
+	an, apre, aok := parseVersion(a)
+	bn, bpre, bok := parseVersion(b)
+	if !aok || !bok {
+		return aok && !bok
+	}
+	for i := 0; i < len(an) || i < len(bn); i++ {
+		var x, y int
+		if i < len(an) {
+			x = an[i]
+		}
+		if i < len(bn) {
+			y = bn[i]
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	if len(apre) == 0 || len(bpre) == 0 {
+		return len(apre) > 0 && len(bpre) == 0
+	}
+	return apre < bpre
+}
+
+// parseVersion splits a version such as v1.2.3-rc1 into its numbers and
+// pre-release, and reports whether it's a version number at all.
+func parseVersion(v string) ([]int, string, bool) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	var pre string
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+
+	var nums []int
+	for _, part := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, "", false
+		}
+		nums = append(nums, n)
+	}
+	return nums, pre, true
+}
+
+// describeField describes a field change, e.g. field request.name changed
+// from string to int64.
+func describeField(c snapshot.Change) string {
+	switch c.Type {
+	case snapshot.Added:
+		return fmt.Sprintf("field %s added", c.Name)
+	case snapshot.Removed:
+		return fmt.Sprintf("field %s removed", c.Name)
+	}
+	return fmt.Sprintf("field %s changed from %s to %s", c.Name, c.From, c.To)
+}
+
+func hasEndpoint(srv *mregistry.Service, name string) bool {
+	return endpoint(srv, name) != nil
+}
+
+func endpoint(srv *mregistry.Service, name string) *mregistry.Endpoint {
+	for _, ep := range srv.Endpoints {
+		if ep.Name == name {
+			return ep
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+	mregistry "go-micro.dev/v4/registry"
+	"gopkg.in/yaml.v2"
+)
+
+// Register registers a single node of a service, for example a service not
+// built with go-micro, and keeps the registration alive until interrupted,
+// after which it is deregistered. Exits on error.
+func Register(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 || len(ctx.String("address")) == 0 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+	name := ctx.Args().First()
+
+	md, err := parseMetadata(ctx.StringSlice("metadata"))
+	if err != nil {
+		return err
+	}
+
+	r := *mcli.DefaultOptions().Registry
+	if _, ok := md["registry"]; !ok {
+		md["registry"] = r.String()
+	}
+
+	id := ctx.String("id")
+	if len(id) == 0 {
+		id = name + "-" + uuid.New().String()
+	}
+
+	srv := &mregistry.Service{
+		Name:     name,
+		Version:  ctx.String("version"),
+		Metadata: make(map[string]string),
+		Nodes: []*mregistry.Node{{
+			Id:       id,
+			Address:  ctx.String("address"),
+			Metadata: md,
+		}},
+	}
+
+	if path := ctx.String("endpoints"); len(path) > 0 {
+		srv.Endpoints, err = readEndpoints(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("registering node %s\n", id)
+	return keepalive(r, []*mregistry.Service{srv}, ctx.Duration("ttl"), ctx.Duration("interval"))
+}
+
+// Deregister removes a single node of a service from the registry, e.g. a
+// stale entry left behind by a crashed instance. Exits on error.
+func Deregister(ctx *cli.Context) error {
+	args := ctx.Args().Slice()
+	if len(args) != 2 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	r := *mcli.DefaultOptions().Registry
+	srvs, err := r.GetService(args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, srv := range srvs {
+		for _, node := range srv.Nodes {
+			if node.Id != args[1] {
+				continue
+			}
+
+			if err := r.Deregister(&mregistry.Service{
+				Name:     srv.Name,
+				Version:  srv.Version,
+				Metadata: srv.Metadata,
+				Nodes:    []*mregistry.Node{node},
+			}); err != nil {
+				return err
+			}
+
+			fmt.Printf("deregistered node %s of %s %s\n", node.Id, srv.Name, srv.Version)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("node %s of service %s not found", args[1], args[0])
+}
+
+// parseMetadata parses key=value pairs into a map.
+func parseMetadata(pairs []string) (map[string]string, error) {
+	md := make(map[string]string)
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("invalid metadata %q, expected key=value", p)
+		}
+		md[kv[0]] = kv[1]
+	}
+	return md, nil
+}
+
+// readEndpoints reads a YAML list of endpoints, in the format printed by
+// describe service --format=yaml.
+func readEndpoints(path string) ([]*mregistry.Endpoint, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var eps []*mregistry.Endpoint
+	if err := yaml.Unmarshal(b, &eps); err != nil {
+		return nil, fmt.Errorf("failed to read endpoints from %s: %v", path, err)
+	}
+	return eps, nil
+}
@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"time"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/urfave/cli/v2"
+)
+
+var keepaliveFlags []cli.Flag = []cli.Flag{
+	&cli.DurationFlag{
+		Name:  "ttl",
+		Value: time.Minute,
+		Usage: "time to live of the registrations",
+	},
+	&cli.DurationFlag{
+		Name:  "interval",
+		Value: 30 * time.Second,
+		Usage: "interval on which the registrations are renewed",
+	},
+}
+
+func init() {
+	mcli.Register(&cli.Command{
+		Name:  "registry",
+		Usage: "Manage the contents of the registry",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "deregister",
+				Usage:  "Remove a service node from the registry, e.g. " + mcli.App().Name + " registry deregister helloworld helloworld-9660f06a-d608-43d9-9f44-e264ff63c554",
+				Action: Deregister,
+			},
+			{
+				Name:   "diff",
+				Usage:  "Compare two registry snapshots, or a snapshot with the live registry, e.g. " + mcli.App().Name + " registry diff before.yaml [after.yaml]",
+				Action: Diff,
+			},
+			{
+				Name:   "export",
+				Usage:  "Export a snapshot of all services in the registry, e.g. " + mcli.App().Name + " registry export > snap.yaml",
+				Action: Export,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "yaml",
+						Usage: "output format of the snapshot, e.g. json or yaml",
+					},
+				},
+			},
+			{
+				Name:   "import",
+				Usage:  "Register the services of a snapshot until interrupted, e.g. " + mcli.App().Name + " registry import snap.yaml",
+				Action: Import,
+				Flags:  keepaliveFlags,
+			},
+			{
+				Name:   "lint",
+				Usage:  "Check the registry, or a snapshot, for inconsistent services and endpoints that differ between versions, e.g. " + mcli.App().Name + " registry lint [snap.yaml]",
+				Action: Lint,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "strict",
+						Usage: "exit with an error on warnings too",
+					},
+				},
+			},
+			{
+				Name:   "register",
+				Usage:  "Register a service node until interrupted, e.g. " + mcli.App().Name + " registry register --address=10.0.0.1:8080 legacy",
+				Action: Register,
+				Flags: append([]cli.Flag{
+					&cli.StringFlag{
+						Name:  "address",
+						Usage: "address of the node, e.g. 10.0.0.1:8080",
+					},
+					&cli.StringFlag{
+						Name:  "version",
+						Value: "latest",
+						Usage: "version of the service",
+					},
+					&cli.StringFlag{
+						Name:  "id",
+						Usage: "id of the node, defaults to the service name followed by a UUID",
+					},
+					&cli.StringSliceFlag{
+						Name:  "metadata",
+						Usage: "node metadata, e.g. --metadata protocol=http",
+					},
+					&cli.StringFlag{
+						Name:  "endpoints",
+						Usage: "YAML file holding a list of endpoints, as printed by describe service --format=yaml",
+					},
+				}, keepaliveFlags...),
+			},
+		},
+	})
+}
@@ -0,0 +1,85 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// builder compiles a service to a new binary for every build, so that a
+// failed build never replaces the running instance, and concurrent runs and
+// builds never write the same binary.
+type builder struct {
+	dir   string
+	name  string
+	flags []string
+	// args are the arguments the binary runs with
+	args []string
+	// debug is the address of the debugger the binary runs under, if any
+	debug string
+}
+
+// newBuilder returns a builder for the main package in dir, using the build
+// flags passed to run.
+func newBuilder(ctx *cli.Context, name, dir string, args []string) *builder {
+	b := &builder{
+		dir:  dir,
+		name: strings.Trim(strings.NewReplacer("/", "-", `\`, "-").Replace(name), "-"),
+		args: args,
+	}
+	// disable optimizations and inlining, so the debugger can step through
+	// the code and inspect variables
+	if ctx.Bool("debug") {
+		b.flags = append(b.flags, "-gcflags", "all=-N -l")
+	}
+	if ctx.Bool("race") {
+		b.flags = append(b.flags, "-race")
+	}
+	if tags := ctx.String("tags"); len(tags) > 0 {
+		b.flags = append(b.flags, "-tags", tags)
+	}
+	if ldflags := ctx.String("ldflags"); len(ldflags) > 0 {
+		b.flags = append(b.flags, "-ldflags", ldflags)
+	}
+	return b
+}
+
+// Build compiles the service to a new binary with a unique name, and returns
+// its path. The compiler output is returned as the error of a failed build.
+func (b *builder) Build() (string, error) {
+	dir := filepath.Join(os.TempDir(), "micro", "bin")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	pattern := b.name + "-*"
+	if runtime.GOOS == "windows" {
+		pattern += ".exe"
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	binary := f.Name()
+
+	// VCS stamping fails for repositories without commits, and isn't needed
+	// for development builds
+	args := append([]string{"build", "-buildvcs=false", "-o", binary}, b.flags...)
+	cmd := exec.Command("go", append(args, ".")...)
+	cmd.Dir = b.dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(binary)
+		if len(out) == 0 {
+			return "", err
+		}
+		return "", fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+
+	return binary, nil
+}
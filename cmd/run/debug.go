@@ -0,0 +1,54 @@
+package run
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/runtime"
+)
+
+// DefaultDebugPort is the port of the debugger of the first service.
+const DefaultDebugPort = 2345
+
+// debugAddress returns the address the debugger of the i-th service listens
+// on, or nothing when not debugging.
+func debugAddress(ctx *cli.Context, i int) (string, error) {
+	if !ctx.Bool("debug") {
+		return "", nil
+	}
+	if _, err := exec.LookPath("dlv"); err != nil {
+		return "", fmt.Errorf("debugging requires delve, install it with: go install github.com/go-delve/delve/cmd/dlv@latest")
+	}
+	return fmt.Sprintf("127.0.0.1:%d", ctx.Int("debug-port")+i), nil
+}
+
+// Options returns the runtime options that run a binary built by the builder
+// with its args. When debugging, the binary runs under a headless delve
+// server, which continues the program right away and accepts clients at any
+// time.
+func (b *builder) Options(binary string) []runtime.CreateOption {
+	if len(b.debug) == 0 {
+		return []runtime.CreateOption{runtime.WithCommand(binary), runtime.WithArgs(b.args...)}
+	}
+
+	dlv := []string{
+		"exec", binary,
+		"--headless",
+		"--listen=" + b.debug,
+		"--api-version=2",
+		"--accept-multiclient",
+		"--continue",
+	}
+	if len(b.args) > 0 {
+		dlv = append(append(dlv, "--"), b.args...)
+	}
+	return []runtime.CreateOption{runtime.WithCommand("dlv"), runtime.WithArgs(dlv...)}
+}
+
+// printDebugger prints the address to connect a debugger to the service.
+func printDebugger(name string, b *builder) {
+	if b != nil && len(b.debug) > 0 {
+		fmt.Printf("debugger for %s listening on %s, connect with: dlv connect %s\n", name, b.debug, b.debug)
+	}
+}
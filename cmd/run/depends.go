@@ -0,0 +1,65 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go-micro.dev/v4/registry"
+)
+
+// DefaultWaitTimeout is how long to wait for the dependencies of a service to
+// be registered before giving up.
+var DefaultWaitTimeout = time.Minute
+
+// waitFor blocks until every service has at least one node in the registry.
+// It logs which services it's waiting for, and gives up when the timeout
+// expires or a signal is received.
+func waitFor(reg registry.Registry, name string, deps []string, timeout time.Duration, sig <-chan os.Signal) error {
+	if len(deps) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	deadline := time.After(timeout)
+	poll := time.NewTicker(500 * time.Millisecond)
+	defer poll.Stop()
+
+	var logged time.Time
+	for {
+		var missing []string
+		for _, dep := range deps {
+			srvs, err := reg.GetService(dep)
+			if err != nil || !hasNodes(srvs) {
+				missing = append(missing, dep)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+
+		if time.Since(logged) >= 10*time.Second {
+			fmt.Printf("%s is waiting for %s to be registered\n", name, strings.Join(missing, ", "))
+			logged = time.Now()
+		}
+
+		select {
+		case <-poll.C:
+		case <-deadline:
+			return fmt.Errorf("%s: timed out after %v waiting for %s to be registered",
+				name, time.Since(start).Round(time.Second), strings.Join(missing, ", "))
+		case s := <-sig:
+			return fmt.Errorf("%s: %v while waiting for %s", name, s, strings.Join(missing, ", "))
+		}
+	}
+}
+
+func hasNodes(srvs []*registry.Service) bool {
+	for _, srv := range srvs {
+		if len(srv.Nodes) > 0 {
+			return true
+		}
+	}
+	return false
+}
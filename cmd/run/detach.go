@@ -0,0 +1,79 @@
+package run
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// detach starts this run again in the background and waits until it started
+// its services. The output of the run is appended to the run log, and printed
+// if it exits before starting its services.
+func detach() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(os.TempDir(), "micro", "logs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	log, err := os.OpenFile(filepath.Join(dir, "run.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	var offset int64
+	if info, err := log.Stat(); err == nil {
+		offset = info.Size()
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), detachEnv+"=1")
+	cmd.Stdout = log
+	cmd.Stderr = log
+	cmd.SysProcAttr = detachAttr()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	t := time.NewTicker(100 * time.Millisecond)
+	defer t.Stop()
+
+	for {
+		select {
+		case err := <-exited:
+			if f, ferr := os.Open(log.Name()); ferr == nil {
+				f.Seek(offset, io.SeekStart)
+				io.Copy(os.Stdout, f)
+				f.Close()
+			}
+			if err == nil {
+				return fmt.Errorf("run exited before starting its services")
+			}
+			return fmt.Errorf("run exited before starting its services: %v", err)
+		case <-t.C:
+			b, err := os.ReadFile(stateFile(cmd.Process.Pid))
+			if err != nil {
+				continue
+			}
+			var s state
+			if err := json.Unmarshal(b, &s); err != nil {
+				continue
+			}
+			fmt.Printf("started %s in the background, pid %d\n", s.Names(), s.PID)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package run
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// DefaultEnvFiles are the env files loaded from the service directory when
+// no env file is passed, in order of preference.
+var DefaultEnvFiles = []string{".env", clusterEnvFile}
+
+// clusterEnvFile is the env file generated for kubernetes deployments. Its
+// MICRO_ variables, e.g. MICRO_REGISTRY=kubernetes, only work in a cluster,
+// so they're skipped when it's loaded by default.
+const clusterEnvFile = "resources/base/app.env"
+
+// environment is the environment a service is started with, on top of the
+// inherited environment.
+type environment map[string]string
+
+// Set sets a variable, overriding its previous value.
+func (e environment) Set(key, value string) {
+	e[key] = value
+}
+
+// Has reports whether the variable is set, or inherited.
+func (e environment) Has(key string) bool {
+	if _, ok := e[key]; ok {
+		return true
+	}
+	_, ok := os.LookupEnv(key)
+	return ok
+}
+
+// List returns the environment as sorted KEY=VALUE pairs.
+func (e environment) List() []string {
+	var env []string
+	for k, v := range e {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// serviceEnv returns the environment of the service in dir. Variables are
+// taken from the env file, the manifest and the env flag, each overriding
+// the previous. Variables of the env file don't override the inherited
+// environment.
+func serviceEnv(ctx *cli.Context, dir string, manifest map[string]string) (environment, error) {
+	env := make(environment)
+
+	file := ctx.String("env-file")
+	var cluster bool
+	if len(file) == 0 {
+		for _, f := range DefaultEnvFiles {
+			if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+				file = filepath.Join(dir, f)
+				cluster = f == clusterEnvFile
+				break
+			}
+		}
+	}
+	if len(file) > 0 {
+		vars, err := readEnvFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var skipped []string
+		for k, v := range vars {
+			if cluster && strings.HasPrefix(k, "MICRO_") {
+				skipped = append(skipped, k)
+				continue
+			}
+			if _, ok := os.LookupEnv(k); !ok {
+				env.Set(k, v)
+			}
+		}
+
+		if len(skipped) > 0 {
+			sort.Strings(skipped)
+			fmt.Printf("loaded env from %s, skipping %s as it only works in a cluster\n", file, strings.Join(skipped, ", "))
+		} else {
+			fmt.Printf("loaded env from %s\n", file)
+		}
+	}
+
+	for k, v := range manifest {
+		env.Set(k, v)
+	}
+
+	for _, kv := range ctx.StringSlice("env") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("invalid env %q, expected KEY=VALUE", kv)
+		}
+		env.Set(parts[0], parts[1])
+	}
+
+	return env, nil
+}
+
+// readEnvFile parses a file of KEY=VALUE lines. Blank lines, comments and an
+// export prefix are allowed, and values may be quoted.
+func readEnvFile(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || len(key) == 0 {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", file, n)
+		}
+
+		value := strings.TrimSpace(parts[1])
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+
+	return vars, scanner.Err()
+}
+
+// freeAddress returns a server address on a port that is currently free.
+func freeAddress() (string, error) {
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return fmt.Sprintf(":%d", l.Addr().(*net.TCPAddr).Port), nil
+}
@@ -0,0 +1,123 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultHooks regenerate code with the Makefile targets of services created
+// with the new command.
+var DefaultHooks = []string{
+	"*.proto=make proto",
+	"postgres/queries/*.sql=make sqlc",
+}
+
+// hook is a command run before restarting a service when a file matching its
+// pattern changed.
+type hook struct {
+	// pattern is a glob matched against the path of the file relative to the
+	// service directory if it contains a slash, and its name otherwise.
+	pattern string
+	command []string
+	// optional hooks are skipped if the service has no Makefile.
+	optional bool
+}
+
+// parseHooks parses hooks in the form pattern=command.
+func parseHooks(specs []string, optional bool) ([]hook, error) {
+	var hooks []hook
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 || len(strings.Fields(parts[1])) == 0 {
+			return nil, fmt.Errorf("invalid hook %q, expected pattern=command", spec)
+		}
+		pattern := filepath.ToSlash(strings.TrimSpace(parts[0]))
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid hook pattern %q: %v", pattern, err)
+		}
+		hooks = append(hooks, hook{
+			pattern:  pattern,
+			command:  strings.Fields(parts[1]),
+			optional: optional,
+		})
+	}
+	return hooks, nil
+}
+
+// Match reports whether a change to the file, relative to the service
+// directory, triggers the hook.
+func (h hook) Match(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	if !strings.Contains(h.pattern, "/") {
+		rel = path.Base(rel)
+	}
+	ok, _ := path.Match(h.pattern, rel)
+	return ok
+}
+
+// Ext returns the extension of the files the hook matches, or an empty
+// string if its pattern has no literal extension.
+func (h hook) Ext() string {
+	ext := path.Ext(h.pattern)
+	if strings.ContainsAny(ext, "*?[\\") {
+		return ""
+	}
+	return ext
+}
+
+func (h hook) String() string {
+	return strings.Join(h.command, " ")
+}
+
+// runHooks runs every hook matching one of the changed files in dir, once
+// each and in order. The output of a failed hook is printed, and an error
+// returned so the service isn't restarted with stale generated code.
+func runHooks(dir string, hooks []hook, files []string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "Makefile"))
+	hasMakefile := err == nil
+
+	for _, h := range hooks {
+		if h.optional && !hasMakefile {
+			continue
+		}
+
+		matched := false
+		for _, file := range files {
+			f, err := filepath.Abs(file)
+			if err != nil {
+				continue
+			}
+			rel, err := filepath.Rel(abs, f)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			if h.Match(rel) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		fmt.Printf("running %s\n", h)
+		cmd := exec.Command(h.command[0], h.command[1:]...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			os.Stdout.Write(out)
+			return fmt.Errorf("%s failed: %v", h, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package run
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// generated matches the header Go tools write to generated files.
+var generated = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// pattern is a single .gitignore or --ignore pattern.
+type pattern struct {
+	glob     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// filter decides which changed files trigger a restart.
+type filter struct {
+	root     string
+	exts     map[string]bool
+	patterns []pattern
+}
+
+// newFilter returns a filter that only accepts files with the given
+// extensions, and rejects files matching the .gitignore in dir or any of the
+// ignore globs.
+func newFilter(dir string, exts, ignore []string) *filter {
+	f := &filter{root: dir, exts: make(map[string]bool)}
+	for _, ext := range exts {
+		ext = strings.TrimSpace(ext)
+		if len(ext) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		f.exts[ext] = true
+	}
+
+	if b, err := os.ReadFile(filepath.Join(dir, ".gitignore")); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(b))
+		for scanner.Scan() {
+			f.add(scanner.Text())
+		}
+	}
+	for _, glob := range ignore {
+		f.add(glob)
+	}
+
+	return f
+}
+
+// add parses a pattern in .gitignore syntax.
+func (f *filter) add(line string) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	var p pattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	// **/ matches in every directory, so it doesn't anchor the pattern
+	line = strings.TrimPrefix(line, "**/")
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if len(line) == 0 {
+		return
+	}
+
+	p.glob = line
+	f.patterns = append(f.patterns, p)
+}
+
+// Ignored reports whether the path, within the project directory, is matched
+// by the ignore patterns. The last matching pattern wins, as in .gitignore.
+func (f *filter) Ignored(file string, dir bool) bool {
+	rel, err := filepath.Rel(f.root, file)
+	if err != nil {
+		rel = file
+	}
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+
+	ignored := false
+	for _, p := range f.patterns {
+		if p.match(parts, dir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// match reports whether the pattern matches the path or one of its parent
+// directories.
+func (p pattern) match(parts []string, dir bool) bool {
+	for i := range parts {
+		// parents of the path are directories
+		isDir := dir || i < len(parts)-1
+		if p.dirOnly && !isDir {
+			continue
+		}
+
+		if p.anchored {
+			if ok, _ := path.Match(p.glob, strings.Join(parts[:i+1], "/")); ok {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p.glob, parts[i]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Accept reports whether a change to the file should trigger a restart.
+func (f *filter) Accept(file string) bool {
+	if len(f.exts) > 0 && !f.exts[filepath.Ext(file)] {
+		return false
+	}
+	if f.Ignored(file, false) {
+		return false
+	}
+	return !isGenerated(file)
+}
+
+// isGenerated reports whether the file starts with a generated code header.
+func isGenerated(file string) bool {
+	if filepath.Ext(file) != ".go" {
+		return false
+	}
+
+	fd, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer fd.Close()
+
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if generated.MatchString(line) {
+			return true
+		}
+		// the header must appear before the package clause
+		if strings.HasPrefix(line, "package ") {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package run
+
+import (
+	"fmt"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/runtime"
+)
+
+func init() {
+	mcli.Register(&cli.Command{
+		Name:   "logs",
+		Usage:  "Print the logs of a service started with run, e.g. " + mcli.App().Name + " logs -f helloworld",
+		Action: Logs,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "follow",
+				Aliases: []string{"f"},
+				Usage:   "keep printing new lines as they are logged",
+			},
+			&cli.IntFlag{
+				Name:    "lines",
+				Aliases: []string{"n"},
+				Value:   100,
+				Usage:   "number of existing lines to print",
+			},
+		},
+	})
+}
+
+// Logs prints the last lines logged by a service, and keeps printing new lines
+// if following. Exits on error.
+func Logs(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	r := *mcli.DefaultCLI.Options().Runtime
+	svc := &runtime.Service{Name: ctx.Args().First()}
+	n := ctx.Int("lines")
+
+	stream, err := r.Logs(svc, runtime.LogsCount(int64(n)))
+	if err != nil {
+		return err
+	}
+
+	// the runtime estimates the offset of the last lines, so it may return
+	// more than asked for
+	var lines []string
+	for rec := range stream.Chan() {
+		lines = append(lines, rec.Message)
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	if err := stream.Error(); err != nil || !ctx.Bool("follow") {
+		return err
+	}
+
+	stream, err = r.Logs(svc, runtime.LogsStream(true))
+	if err != nil {
+		return err
+	}
+	defer stream.Stop()
+
+	for rec := range stream.Chan() {
+		fmt.Println(rec.Message)
+	}
+	return stream.Error()
+}
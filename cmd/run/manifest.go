@@ -0,0 +1,162 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultManifest is the project manifest run reads when no service is passed.
+const DefaultManifest = "micro.yaml"
+
+// manifest describes the services of a project that are run together, e.g.
+//
+//	services:
+//	  users:
+//	    path: ./users
+//	    env:
+//	      DATABASE_URL: postgres://localhost/users
+//	  api:
+//	    path: ./api
+//	    command: go
+//	    args: [run, ., --verbose]
+//	    depends_on: [users]
+type manifest struct {
+	Services map[string]*serviceConfig `yaml:"services"`
+}
+
+// serviceConfig is a single service of the manifest.
+type serviceConfig struct {
+	// Name of the service, set from its key in the manifest.
+	Name string `yaml:"-"`
+	// Path is the directory of the service, relative to the manifest.
+	Path string `yaml:"path"`
+	// Command overrides the command used to run the service.
+	Command string `yaml:"command,omitempty"`
+	// Args are the arguments passed to the command.
+	Args []string `yaml:"args,omitempty"`
+	// Env is added to the environment of the service.
+	Env map[string]string `yaml:"env,omitempty"`
+	// DependsOn lists the services that must be registered before this one
+	// starts. Services of the manifest among them are started first.
+	DependsOn []string `yaml:"depends_on,omitempty"`
+}
+
+// readManifest reads and validates a manifest. Service paths are resolved
+// relative to the directory of the manifest.
+func readManifest(file string) (*manifest, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+	}
+	if len(m.Services) == 0 {
+		return nil, fmt.Errorf("%s defines no services", file)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+
+	for name, srv := range m.Services {
+		if srv == nil {
+			srv = &serviceConfig{}
+			m.Services[name] = srv
+		}
+		if strings.ContainsAny(name, `/\ `) {
+			return nil, fmt.Errorf("invalid service name %q in %s", name, file)
+		}
+		srv.Name = name
+		if len(srv.Path) == 0 {
+			srv.Path = name
+		}
+		if !filepath.IsAbs(srv.Path) {
+			srv.Path = filepath.Join(dir, srv.Path)
+		}
+		if info, err := os.Stat(srv.Path); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("service %s: %s is not a directory", name, srv.Path)
+		}
+	}
+
+	return &m, nil
+}
+
+// Order returns the services in the order they must be started, so that
+// every service starts after its dependencies. Services without a dependency
+// between them are ordered by name.
+func (m *manifest) Order() ([]*serviceConfig, error) {
+	var names []string
+	for name := range m.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var order []*serviceConfig
+	state := make(map[string]int)
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+		state[name] = 1
+
+		deps := append([]string{}, m.Services[name].DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			// services outside of the manifest are only waited for
+			if _, ok := m.Services[dep]; !ok {
+				continue
+			}
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+
+		state[name] = 2
+		order = append(order, m.Services[name])
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// Owner returns the service whose directory contains the file, or nil if the
+// file is outside of every service.
+func (m *manifest) Owner(file string) *serviceConfig {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil
+	}
+
+	var owner *serviceConfig
+	for _, srv := range m.Services {
+		rel, err := filepath.Rel(srv.Path, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		// nested services own the files in their directory
+		if owner == nil || len(srv.Path) > len(owner.Path) {
+			owner = srv
+		}
+	}
+	return owner
+}
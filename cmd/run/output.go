@@ -0,0 +1,81 @@
+package run
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// colors are the ANSI colors cycled through for service name prefixes.
+var colors = []int{36, 33, 32, 35, 34, 31}
+
+// output multiplexes the output of several services onto a single writer,
+// prefixing each line with the name of the service it came from.
+type output struct {
+	sync.Mutex
+
+	w     io.Writer
+	color bool
+	width int
+	count int
+}
+
+// newOutput returns an output writing to stdout. Names are colored when
+// stdout is a terminal.
+func newOutput(names []string) *output {
+	o := &output{w: os.Stdout}
+	if info, err := os.Stdout.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		o.color = len(os.Getenv("NO_COLOR")) == 0
+	}
+	for _, name := range names {
+		if len(name) > o.width {
+			o.width = len(name)
+		}
+	}
+	return o
+}
+
+// Writer returns a writer for the output of a service.
+func (o *output) Writer(name string) io.Writer {
+	o.Lock()
+	defer o.Unlock()
+
+	prefix := fmt.Sprintf("%-*s | ", o.width, name)
+	if o.color {
+		prefix = fmt.Sprintf("\033[%dm%s\033[0m", colors[o.count%len(colors)], prefix)
+	}
+	o.count++
+
+	return &prefixWriter{output: o, prefix: []byte(prefix)}
+}
+
+// prefixWriter writes complete lines with a prefix, buffering partial lines
+// until they are terminated. Lines of different services never interleave.
+type prefixWriter struct {
+	*output
+
+	prefix []byte
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := append(append([]byte{}, p.prefix...), p.buf[:i+1]...)
+		if _, err := p.w.Write(line); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+
+	return len(b), nil
+}
@@ -0,0 +1,38 @@
+//go:build !windows
+
+package run
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachAttr starts a detached run in a new session, so it keeps running
+// when the terminal it was started from is closed.
+func detachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// alive reports whether a process is running.
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// groupAttr starts a service in its own process group, so that stopping it
+// stops the processes it started too, e.g. the binary of go run.
+func groupAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminate asks the process group of a service to exit.
+func terminate(p *os.Process) error {
+	err := syscall.Kill(-p.Pid, syscall.SIGTERM)
+	if err == syscall.ESRCH {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package run
+
+import (
+	"os"
+	"syscall"
+)
+
+const (
+	// stillActive is the exit code of a process that hasn't exited.
+	stillActive = 259
+	// detachedProcess starts a process without a console.
+	detachedProcess = 0x00000008
+)
+
+// detachAttr starts a detached run without a console, in a new process group,
+// so it keeps running when the console it was started from is closed, and
+// doesn't receive its Ctrl+C.
+func detachAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
+
+// alive reports whether a process is running. Signals other than kill aren't
+// supported on windows, so its exit code is queried instead.
+func alive(pid int) bool {
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// groupAttr returns no attributes, as process groups can't be signalled on
+// windows.
+func groupAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// terminate kills the process of a service, as it can't be asked to exit on
+// windows.
+func terminate(p *os.Process) error {
+	err := p.Kill()
+	if err == os.ErrProcessDone {
+		return nil
+	}
+	return err
+}
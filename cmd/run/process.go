@@ -0,0 +1,97 @@
+package run
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"go-micro.dev/v4/runtime"
+	"go-micro.dev/v4/runtime/local/git"
+)
+
+// process is a service started by its supervisor rather than by the local
+// runtime, so the supervisor learns about its exit by waiting on it.
+type process struct {
+	cmd *exec.Cmd
+	// done is closed once the process exited, with err set to its exit error
+	done chan bool
+	err  error
+}
+
+// startProcess starts a service with the command, arguments, environment and
+// output of the create options, like the local runtime does. The output is
+// appended to the log file of the service too, which the logs command reads.
+func startProcess(svc *runtime.Service, opts []runtime.CreateOption) (*process, error) {
+	var options runtime.CreateOptions
+	for _, o := range opts {
+		o(&options)
+	}
+	if len(options.Command) == 0 {
+		options.Command = []string{"go"}
+		options.Args = []string{"run", "."}
+	}
+
+	dir := filepath.Join(os.TempDir(), "micro", "logs")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	name := strings.ReplaceAll(svc.Name, "/", "-") + ".log"
+	log, err := os.OpenFile(filepath.Join(dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	var out io.Writer = log
+	if options.Output != nil {
+		out = io.MultiWriter(options.Output, log)
+	}
+
+	cmd := exec.Command(strings.Join(options.Command, " "), options.Args...)
+	cmd.Dir = svc.Source
+	cmd.Env = append(os.Environ(), options.Env...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = groupAttr()
+	if err := cmd.Start(); err != nil {
+		log.Close()
+		return nil, err
+	}
+
+	p := &process{cmd: cmd, done: make(chan bool)}
+	go func() {
+		p.err = cmd.Wait()
+		log.Close()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// stop terminates the process and waits for it to exit.
+func (p *process) stop() {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	if err := terminate(p.cmd.Process); err != nil {
+		fmt.Printf("failed to stop process %d: %v\n", p.cmd.Process.Pid, err)
+	}
+	<-p.done
+}
+
+// checkout checks out the source of a service that isn't local, and points
+// the service at the checkout, like the local runtime does.
+func checkout(svc *runtime.Service) error {
+	source, err := git.ParseSourceLocal("", svc.Source)
+	if err != nil {
+		return err
+	}
+	source.Ref = svc.Version
+	if err := git.CheckoutSource(os.TempDir(), source); err != nil {
+		return err
+	}
+	svc.Source = source.FullPath
+	return nil
+}
@@ -0,0 +1,80 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/runtime"
+)
+
+func init() {
+	mcli.Register(&cli.Command{
+		Name:   "ps",
+		Usage:  "List the services started with run, e.g. " + mcli.App().Name + " ps",
+		Action: Ps,
+	})
+}
+
+// Ps lists the services managed by the runtime with their status, uptime and
+// restart count. Services of the local runtime are listed from the records of
+// detached runs. Exits on error.
+func Ps(ctx *cli.Context) error {
+	svcs, err := listServices()
+	if err != nil {
+		return err
+	}
+	if len(svcs) == 0 {
+		fmt.Println("no services running")
+		return nil
+	}
+
+	sort.Slice(svcs, func(i, j int) bool {
+		return svcs[i].Name < svcs[j].Name
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tSTATUS\tUPTIME\tRESTARTS\tSOURCE")
+	for _, svc := range svcs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			svc.Name,
+			orDash(svc.Version),
+			orDash(svc.Metadata["status"]),
+			uptime(svc),
+			orDash(svc.Metadata["restarts"]),
+			orDash(svc.Source),
+		)
+	}
+	return w.Flush()
+}
+
+// listServices returns the services of the runtime, or the services of all
+// detached runs for the local runtime, which only knows the services of its
+// own process.
+func listServices() ([]*runtime.Service, error) {
+	r := *mcli.DefaultCLI.Options().Runtime
+	if r.String() != "local" {
+		return r.Read()
+	}
+
+	states, err := readStates()
+	if err != nil {
+		return nil, err
+	}
+
+	var svcs []*runtime.Service
+	for _, s := range states {
+		svcs = append(svcs, s.Services...)
+	}
+	return svcs, nil
+}
+
+func orDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
@@ -2,12 +2,12 @@ package run
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/urfave/cli/v2"
 	mcli "github.com/go-micro/cli/cmd"
 	"go-micro.dev/v4/runtime"
@@ -18,6 +18,11 @@ var (
 	DefaultRetries = 3
 
 	flags []cli.Flag = []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "detach",
+			Aliases: []string{"d"},
+			Usage:   "run in the background, see the ps, logs and stop commands",
+		},
 		&cli.StringFlag{
 			Name:  "command",
 			Usage: "command to execute",
@@ -26,10 +31,82 @@ var (
 			Name:  "args",
 			Usage: "command arguments",
 		},
+		&cli.StringFlag{
+			Name:  "env-file",
+			Usage: "file of KEY=VALUE lines to set in the environment of the service, defaults to .env or resources/base/app.env",
+		},
+		&cli.StringSliceFlag{
+			Name:  "env",
+			Usage: "environment variable to set for the service, as KEY=VALUE",
+		},
+		&cli.BoolFlag{
+			Name:  "race",
+			Usage: "build the service with the race detector enabled",
+		},
+		&cli.StringFlag{
+			Name:  "tags",
+			Usage: "comma separated build tags to build the service with",
+		},
+		&cli.StringFlag{
+			Name:  "ldflags",
+			Usage: "flags to pass to the go linker when building the service",
+		},
+		&cli.BoolFlag{
+			Name:  "debug",
+			Usage: "build without optimizations and run the service under a headless delve server",
+		},
+		&cli.IntFlag{
+			Name:  "debug-port",
+			Value: DefaultDebugPort,
+			Usage: "port of the delve server, incremented for every further service",
+		},
+		&cli.StringSliceFlag{
+			Name:  "hook",
+			Usage: "command run before restarting when a matching file changes, in the form pattern=command, e.g. '*.proto=make proto'",
+		},
+		&cli.StringSliceFlag{
+			Name:  "depends-on",
+			Usage: "service to wait for in the registry before starting the service",
+		},
+		&cli.DurationFlag{
+			Name:  "wait-timeout",
+			Value: DefaultWaitTimeout,
+			Usage: "how long to wait for the services the service depends on to be registered",
+		},
+		&cli.StringFlag{
+			Name:    "file",
+			Aliases: []string{"f"},
+			Value:   DefaultManifest,
+			Usage:   "project manifest listing the services to run when no service is passed",
+		},
+		&cli.StringFlag{
+			Name:  "restart",
+			Value: RestartOnFailure,
+			Usage: "restart policy when the service exits, one of always, on-failure or never",
+		},
+		&cli.IntFlag{
+			Name:  "retries",
+			Value: DefaultRetries,
+			Usage: "number of consecutive exits after which restarts are paused until the next change",
+		},
 		&cli.StringFlag{
 			Name:  "type",
 			Usage: "the type of service to operate on",
 		},
+		&cli.DurationFlag{
+			Name:  "debounce",
+			Value: 500 * time.Millisecond,
+			Usage: "wait for file changes to settle for this duration before restarting",
+		},
+		&cli.StringFlag{
+			Name:  "ext",
+			Value: ".go,.proto,.sql",
+			Usage: "comma separated file extensions that trigger a restart",
+		},
+		&cli.StringSliceFlag{
+			Name:  "ignore",
+			Usage: "glob of files to ignore, in .gitignore syntax, in addition to the .gitignore file",
+		},
 	}
 )
 
@@ -42,9 +119,28 @@ func init() {
 	})
 }
 
-// Run runs a service and watches the project directory for change events. On
-// write, the service is restarted. Exits on error.
+// Run runs a service and watches the project directory for change events.
+// Changes are coalesced over the debounce window, after which the service is
+// restarted once. Files ignored by .gitignore or the ignore flag, generated
+// files and files without one of the watched extensions are skipped. Hooks
+// matching the changed files, such as make proto, run before restarting, and
+// local services are built before being restarted. The service keeps running
+// if either fails. When no service is passed and the project has a manifest,
+// all of its services are run instead. Exits on error.
 func Run(ctx *cli.Context) error {
+	// only the local runtime runs services in this process
+	runtimeName := (*mcli.DefaultCLI.Options().Runtime).String()
+	if ctx.Bool("detach") && !detached() && runtimeName == "local" {
+		return detach()
+	}
+
+	file := ctx.String("file")
+	if ctx.Args().Len() == 0 {
+		if _, err := os.Stat(file); err == nil || ctx.IsSet("file") {
+			return RunManifest(ctx, file)
+		}
+	}
+
 	wd, err := os.Getwd()
 	if err != nil {
 		return err
@@ -62,97 +158,333 @@ func Run(ctx *cli.Context) error {
 		Metadata: make(map[string]string),
 	}
 
-	typ := ctx.String("type")
 	command := strings.TrimSpace(ctx.String("command"))
 	args := strings.TrimSpace(ctx.String("args"))
 
 	r := *mcli.DefaultCLI.Options().Runtime
 
-	var retries = DefaultRetries
-	if ctx.IsSet("retries") {
-		retries = ctx.Int("retries")
+	policy := ctx.String("restart")
+	if !validPolicy(policy) {
+		return cli.ShowCommandHelp(ctx, "run")
+	}
+
+	dir := wd
+	if source.Local {
+		dir = svc.Source
 	}
+	env, err := serviceEnv(ctx, dir, nil)
+	if err != nil {
+		return err
+	}
+
+	opts := append(createOptions(ctx), runtime.WithEnv(env.List()))
 
-	opts := []runtime.CreateOption{
-		runtime.WithOutput(os.Stdout),
-		runtime.WithRetries(retries),
-		runtime.CreateType(typ),
+	// local services are built before starting, unless a command is passed
+	var b *builder
+	if len(command) > 0 || !source.Local {
+		if ctx.Bool("debug") {
+			return fmt.Errorf("debugging requires building a local service without a command")
+		}
+		if len(command) > 0 {
+			opts = append(opts, runtime.WithCommand(command))
+		}
+		if len(args) > 0 {
+			opts = append(opts, runtime.WithArgs(strings.Fields(args)...))
+		}
+	} else {
+		b = newBuilder(ctx, svc.Name, svc.Source, strings.Fields(args))
+		if b.debug, err = debugAddress(ctx, 0); err != nil {
+			return err
+		}
 	}
 
-	if len(command) > 0 {
-		opts = append(opts, runtime.WithCommand(command))
+	sup := newSupervisor(r, svc, b, policy, ctx.Int("retries"))
+	if b != nil {
+		if sup.binary, err = b.Build(); err != nil {
+			fmt.Println(err)
+			return fmt.Errorf("failed to build %s", svc.Name)
+		}
+	}
+	sup.notify = func() {
+		report([]*supervisor{sup})
 	}
+	sup.opts = append(opts, runtime.WithOutput(io.MultiWriter(os.Stdout, sup.tail)))
 
-	if len(args) > 0 {
-		opts = append(opts, runtime.WithArgs(args))
+	sig := make(chan os.Signal, 1)
+	notifyStop(sig)
+
+	reg := *mcli.DefaultOptions().Registry
+	if err := waitFor(reg, svc.Name, ctx.StringSlice("depends-on"), ctx.Duration("wait-timeout"), sig); err != nil {
+		sup.Stop()
+		return err
 	}
 
-	if err := r.Create(svc, opts...); err != nil {
+	if err := sup.Start(); err != nil {
+		sup.Stop()
 		return err
 	}
+	printDebugger(svc.Name, b)
 
 	done := make(chan bool)
 	if r.String() == "local" {
-		sig := make(chan os.Signal)
-		signal.Notify(sig, os.Interrupt)
+		handleStops([]*supervisor{sup}, sig)
 		go func() {
 			<-sig
-			r.Delete(svc)
+			sup.Stop()
+			removeState()
 			done <- true
 		}()
 	}
 
 	if source.Local {
-		watcher, err := fsnotify.NewWatcher()
+		hooks, err := loadHooks(ctx)
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		defer watcher.Close()
 
-		go func() {
-			for {
-				select {
-				case event, ok := <-watcher.Events:
-					if !ok {
-						return
-					}
-					if event.Op&fsnotify.Write == fsnotify.Write {
-						r.Update(svc)
-					}
-					if event.Op&fsnotify.Create == fsnotify.Create {
-						watcher.Add(event.Name)
-					}
-					if event.Op&fsnotify.Remove == fsnotify.Remove {
-						watcher.Remove(event.Name)
-					}
-				case err, ok := <-watcher.Errors:
-					if !ok {
-						return
-					}
-					fmt.Println("ERROR", err)
-				}
+		w, err := watch(ctx, dir, hooks, func(files []string) {
+			fmt.Printf("%s changed, restarting %s\n", describeChanges(files), svc.Name)
+			if err := runHooks(dir, hooks, files); err != nil {
+				fmt.Printf("%v, not restarting %s\n", err, svc.Name)
+				return
 			}
-		}()
+			sup.Restart()
+		})
+		if err != nil {
+			return err
+		}
+		defer w.Close()
+	}
 
-		var files []string
-		filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+	if r.String() == "local" {
+		if detached() {
+			report([]*supervisor{sup})
+		}
+		<-done
+	}
+
+	return nil
+}
+
+// RunManifest runs all services listed in a project manifest, starting each
+// service once its dependencies are registered. The output of every service is prefixed
+// with its name, and a change restarts the service owning the changed files,
+// or every service if the files are outside of all of them. Interrupting
+// stops the services in reverse order. Exits on error.
+func RunManifest(ctx *cli.Context, file string) error {
+	m, err := readManifest(file)
+	if err != nil {
+		return err
+	}
+
+	order, err := m.Order()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, cfg := range order {
+		names = append(names, cfg.Name)
+	}
+	out := newOutput(names)
+
+	hooks, err := loadHooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	r := *mcli.DefaultCLI.Options().Runtime
+
+	policy := ctx.String("restart")
+	if !validPolicy(policy) {
+		return cli.ShowCommandHelp(ctx, "run")
+	}
+
+	var started []*supervisor
+	stop := func() {
+		for i := len(started) - 1; i >= 0; i-- {
+			started[i].Stop()
+		}
+	}
+	notify := func() {
+		report(started)
+	}
+
+	sig := make(chan os.Signal, 1)
+	notifyStop(sig)
+	reg := *mcli.DefaultOptions().Registry
+
+	sups := make(map[string]*supervisor)
+	for i, cfg := range order {
+		svc := &runtime.Service{
+			Name:     cfg.Name,
+			Source:   cfg.Path,
+			Version:  "latest",
+			Metadata: make(map[string]string),
+		}
+
+		env, err := serviceEnv(ctx, cfg.Path, cfg.Env)
+		if err != nil {
+			stop()
+			return fmt.Errorf("service %s: %v", cfg.Name, err)
+		}
+		// services would otherwise compete for the same default port
+		if len(order) > 1 && !env.Has("MICRO_SERVER_ADDRESS") {
+			addr, err := freeAddress()
 			if err != nil {
+				stop()
 				return err
 			}
-			files = append(files, path)
-			return nil
-		})
+			env.Set("MICRO_SERVER_ADDRESS", addr)
+		}
 
-		for _, file := range files {
-			if err := watcher.Add(file); err != nil {
+		opts := append(createOptions(ctx), runtime.WithEnv(env.List()))
+
+		var b *builder
+		if len(cfg.Command) > 0 {
+			opts = append(opts, runtime.WithCommand(cfg.Command), runtime.WithArgs(cfg.Args...))
+		} else {
+			b = newBuilder(ctx, cfg.Name, cfg.Path, cfg.Args)
+			if b.debug, err = debugAddress(ctx, i); err != nil {
+				stop()
 				return err
 			}
 		}
+
+		sup := newSupervisor(r, svc, b, policy, ctx.Int("retries"))
+		if b != nil {
+			if sup.binary, err = b.Build(); err != nil {
+				fmt.Println(err)
+				stop()
+				return fmt.Errorf("failed to build %s", cfg.Name)
+			}
+		}
+		sup.notify = notify
+		sup.opts = append(opts, runtime.WithOutput(io.MultiWriter(out.Writer(cfg.Name), sup.tail)))
+
+		if err := waitFor(reg, cfg.Name, cfg.DependsOn, ctx.Duration("wait-timeout"), sig); err != nil {
+			sup.Stop()
+			stop()
+			return err
+		}
+
+		if err := sup.Start(); err != nil {
+			sup.Stop()
+			stop()
+			return fmt.Errorf("failed to start %s: %v", cfg.Name, err)
+		}
+		printDebugger(cfg.Name, b)
+		started = append(started, sup)
+		sups[cfg.Name] = sup
 	}
 
+	done := make(chan bool)
 	if r.String() == "local" {
+		handleStops(started, sig)
+		go func() {
+			<-sig
+			stop()
+			removeState()
+			done <- true
+		}()
+	}
+
+	dir := filepath.Dir(file)
+	w, err := watch(ctx, dir, hooks, func(files []string) {
+		owned := make(map[string]bool)
+		var outside []string
+		for _, f := range files {
+			if owner := m.Owner(f); owner != nil {
+				owned[owner.Name] = true
+			} else {
+				outside = append(outside, f)
+			}
+		}
+
+		var restarted []string
+		for _, cfg := range order {
+			if len(outside) > 0 || owned[cfg.Name] {
+				restarted = append(restarted, cfg.Name)
+			}
+		}
+		fmt.Printf("%s changed, restarting %s\n", describeChanges(files), strings.Join(restarted, ", "))
+
+		if err := runHooks(dir, hooks, outside); err != nil {
+			fmt.Printf("%v, not restarting %s\n", err, strings.Join(restarted, ", "))
+			return
+		}
+		for _, name := range restarted {
+			if err := runHooks(m.Services[name].Path, hooks, files); err != nil {
+				fmt.Printf("%v, not restarting %s\n", err, name)
+				continue
+			}
+			sups[name].Restart()
+		}
+	})
+	if err != nil {
+		stop()
+		return err
+	}
+	defer w.Close()
+
+	if r.String() == "local" {
+		if detached() {
+			report(started)
+		}
 		<-done
 	}
 
 	return nil
 }
+
+// createOptions returns the runtime options shared by every service.
+func createOptions(ctx *cli.Context) []runtime.CreateOption {
+	return []runtime.CreateOption{
+		runtime.WithRetries(ctx.Int("retries")),
+		runtime.CreateType(ctx.String("type")),
+	}
+}
+
+// loadHooks returns the hooks passed with the hook flag, or the default hooks,
+// which only run for services with a Makefile.
+func loadHooks(ctx *cli.Context) ([]hook, error) {
+	if ctx.IsSet("hook") {
+		return parseHooks(ctx.StringSlice("hook"), false)
+	}
+	return parseHooks(DefaultHooks, true)
+}
+
+// watch recursively watches dir and calls onChange with the files changed
+// after each burst of changes. Files with the extension of a hook pattern are
+// watched too, so the hook runs when they change.
+func watch(ctx *cli.Context, dir string, hooks []hook, onChange func([]string)) (*watcher, error) {
+	exts := strings.Split(ctx.String("ext"), ",")
+	for _, h := range hooks {
+		if ext := h.Ext(); len(ext) > 0 {
+			exts = append(exts, ext)
+		}
+	}
+
+	f := newFilter(dir, exts, ctx.StringSlice("ignore"))
+	w, err := newWatcher(f, ctx.Duration("debounce"), onChange)
+	if err != nil {
+		return nil, err
+	}
+
+	go w.Run()
+
+	if err := w.AddTree(dir); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// describeChanges summarizes a list of changed files, e.g. main.go and 2 more.
+func describeChanges(files []string) string {
+	if len(files) == 1 {
+		return files[0]
+	}
+	return fmt.Sprintf("%s and %d more", files[0], len(files)-1)
+}
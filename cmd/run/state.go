@@ -0,0 +1,190 @@
+package run
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-micro.dev/v4/runtime"
+)
+
+// detachEnv is set in the environment of a run started with --detach.
+const detachEnv = "MICRO_RUN_DETACHED"
+
+// state is the record a detached run keeps of its services, so they can be
+// listed and stopped from other terminal sessions.
+type state struct {
+	// PID is the process id of the run.
+	PID int `json:"pid"`
+	// Dir is the directory the run was started in.
+	Dir string `json:"dir"`
+	// Services are the services of the run. Their metadata holds the status,
+	// start time and restart count.
+	Services []*runtime.Service `json:"services"`
+}
+
+// stateDir returns the directory detached runs record their state in.
+func stateDir() string {
+	return filepath.Join(os.TempDir(), "micro", "run")
+}
+
+func stateFile(pid int) string {
+	return filepath.Join(stateDir(), strconv.Itoa(pid)+".json")
+}
+
+// detached reports whether this run was started with --detach.
+func detached() bool {
+	return len(os.Getenv(detachEnv)) > 0
+}
+
+// writeState records the services of this run.
+func writeState(sups []*supervisor) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	s := &state{PID: os.Getpid(), Dir: wd}
+	for _, sup := range sups {
+		s.Services = append(s.Services, sup.Service())
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stateDir(), 0755); err != nil {
+		return err
+	}
+
+	// write atomically, as the state is read by other processes
+	file := stateFile(s.PID)
+	if err := os.WriteFile(file+".tmp", b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// stopFile is written to ask a detached run to stop one of its services.
+func stopFile(pid int, name string) string {
+	return filepath.Join(stateDir(), fmt.Sprintf("%d-%s.stop", pid, url.PathEscape(name)))
+}
+
+// removeState removes the record of this run.
+func removeState() {
+	os.Remove(stateFile(os.Getpid()))
+	files, _ := filepath.Glob(filepath.Join(stateDir(), strconv.Itoa(os.Getpid())+"-*.stop"))
+	for _, file := range files {
+		os.Remove(file)
+	}
+}
+
+// notifyStop relays interrupts and termination signals to sig.
+func notifyStop(sig chan os.Signal) {
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+}
+
+// handleStops stops the services of a detached run a stop file is written
+// for, and relays an interrupt to sig once all of them are stopped, so the run
+// exits.
+func handleStops(sups []*supervisor, sig chan os.Signal) {
+	if !detached() {
+		return
+	}
+
+	go func() {
+		for range time.Tick(250 * time.Millisecond) {
+			stopped := 0
+			for _, sup := range sups {
+				file := stopFile(os.Getpid(), sup.svc.Name)
+				if _, err := os.Stat(file); err == nil {
+					fmt.Printf("stopping %s\n", sup.svc.Name)
+					sup.Stop()
+					os.Remove(file)
+					report(sups)
+				}
+				if sup.stopped() {
+					stopped++
+				}
+			}
+			if stopped == len(sups) {
+				sig <- os.Interrupt
+				return
+			}
+		}
+	}()
+}
+
+// readStates returns the records of all detached runs that are still
+// running. Records of runs that exited without removing them are removed.
+func readStates() ([]*state, error) {
+	files, err := filepath.Glob(filepath.Join(stateDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var states []*state
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var s state
+		if err := json.Unmarshal(b, &s); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+		}
+		if !alive(s.PID) {
+			os.Remove(file)
+			continue
+		}
+		states = append(states, &s)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].PID < states[j].PID
+	})
+	return states, nil
+}
+
+// findState returns the record of the run a service that isn't stopped yet
+// belongs to.
+func findState(name string) (*state, error) {
+	states, err := readStates()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range states {
+		for _, svc := range s.Services {
+			if svc.Name == name && svc.Metadata["status"] != "stopped" {
+				return s, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("service %s is not running", name)
+}
+
+// Names returns the names of the services of the run.
+func (s *state) Names() string {
+	var names []string
+	for _, svc := range s.Services {
+		names = append(names, svc.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// uptime formats how long ago a service started, according to its metadata.
+func uptime(svc *runtime.Service) string {
+	started, err := time.Parse(time.RFC3339, svc.Metadata["started"])
+	if err != nil {
+		return "-"
+	}
+	return time.Since(started).Round(time.Second).String()
+}
@@ -0,0 +1,66 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/runtime"
+)
+
+func init() {
+	mcli.Register(&cli.Command{
+		Name:   "stop",
+		Usage:  "Stop a service started with run, e.g. " + mcli.App().Name + " stop helloworld",
+		Action: Stop,
+	})
+}
+
+// Stop stops services of the runtime. Services of the local runtime are
+// stopped by asking the detached run they belong to, which exits once all of
+// its services are stopped. Exits on error.
+func Stop(ctx *cli.Context) error {
+	if ctx.Args().Len() == 0 {
+		return cli.ShowSubcommandHelp(ctx)
+	}
+
+	r := *mcli.DefaultCLI.Options().Runtime
+	for _, name := range ctx.Args().Slice() {
+		if r.String() != "local" {
+			if err := r.Delete(&runtime.Service{Name: name}); err != nil {
+				return err
+			}
+			fmt.Printf("stopped %s\n", name)
+			continue
+		}
+
+		s, err := findState(name)
+		if err != nil {
+			return err
+		}
+		if err := stopService(s, name); err != nil {
+			return err
+		}
+		fmt.Printf("stopped %s\n", name)
+	}
+
+	return nil
+}
+
+// stopService asks a detached run to stop a service with a stop file, and
+// waits for the service to stop.
+func stopService(s *state, name string) error {
+	if err := os.WriteFile(stopFile(s.PID, name), nil, 0644); err != nil {
+		return fmt.Errorf("failed to stop %s: %v", name, err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, err := findState(name); err != nil {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("timed out waiting for %s to stop", name)
+}
@@ -0,0 +1,366 @@
+package run
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-micro.dev/v4/runtime"
+)
+
+// Restart policies.
+const (
+	RestartAlways    = "always"
+	RestartOnFailure = "on-failure"
+	RestartNever     = "never"
+)
+
+var (
+	// DefaultBackoff is the delay before restarting a service after its first
+	// exit. The delay doubles with every consecutive exit.
+	DefaultBackoff = time.Second
+	// MaxBackoff is the longest delay before restarting a service.
+	MaxBackoff = 30 * time.Second
+	// StableUptime is how long a service must run for its exits to no longer
+	// count as consecutive.
+	StableUptime = 10 * time.Second
+	// TailLines is the number of output lines printed for a crash loop.
+	TailLines = 20
+)
+
+// supervisor restarts a service when it exits, according to a restart
+// policy, and pauses restarts when the service is crash looping. With the
+// local runtime, the supervisor starts the service process itself and waits
+// on it to learn when it exits. Other runtimes manage the service.
+type supervisor struct {
+	sync.Mutex
+
+	r   runtime.Runtime
+	svc *runtime.Service
+	// opts are the options the service is created with
+	opts    []runtime.CreateOption
+	builder *builder
+	// binary is the binary built by the builder the service runs, if any
+	binary  string
+	policy  string
+	retries int
+	tail    *tail
+	// notify is called when the state of the service changed, without
+	// holding the lock
+	notify func()
+
+	proc     *process
+	state    string
+	started  time.Time
+	exits    int
+	restarts int
+	timer    *time.Timer
+}
+
+func newSupervisor(r runtime.Runtime, svc *runtime.Service, b *builder, policy string, retries int) *supervisor {
+	return &supervisor{
+		r:       r,
+		svc:     svc,
+		builder: b,
+		policy:  policy,
+		retries: retries,
+		tail:    newTail(TailLines),
+		notify:  func() {},
+		state:   "running",
+	}
+}
+
+// validPolicy reports whether the restart policy is known.
+func validPolicy(policy string) bool {
+	switch policy {
+	case RestartAlways, RestartOnFailure, RestartNever:
+		return true
+	}
+	return false
+}
+
+// local reports whether the supervisor runs the service process itself.
+func (s *supervisor) local() bool {
+	return s.r.String() == "local"
+}
+
+// Start starts the service.
+func (s *supervisor) Start() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if !s.local() {
+		s.started = time.Now()
+		return s.r.Create(s.svc, s.options()...)
+	}
+	if err := checkout(s.svc); err != nil {
+		return err
+	}
+	return s.start()
+}
+
+// start starts the service process and waits on it in the background.
+// Assumes it's called under a lock.
+func (s *supervisor) start() error {
+	p, err := startProcess(s.svc, s.options())
+	if err != nil {
+		return err
+	}
+	s.proc = p
+	s.started = time.Now()
+	go s.wait(p)
+	return nil
+}
+
+// options returns the options the service is created with, running the
+// current binary if it's built by run.
+func (s *supervisor) options() []runtime.CreateOption {
+	if s.builder == nil {
+		return s.opts
+	}
+	return append(s.opts[:len(s.opts):len(s.opts)], s.builder.Options(s.binary)...)
+}
+
+// wait applies the restart policy once the process exits, unless it was
+// stopped or replaced in the meantime.
+func (s *supervisor) wait(p *process) {
+	<-p.done
+
+	s.Lock()
+	if s.proc != p {
+		s.Unlock()
+		return
+	}
+	s.proc = nil
+	s.exited(p.err != nil)
+	// the restart itself is reported after the backoff
+	changed := s.state != "restarting"
+	s.Unlock()
+
+	if changed {
+		s.notify()
+	}
+}
+
+// exited applies the restart policy to a service that exited. Assumes it's
+// called under a lock.
+func (s *supervisor) exited(failed bool) {
+	if s.policy == RestartNever || (s.policy == RestartOnFailure && !failed) {
+		s.state = "exited"
+		fmt.Printf("%s exited, not restarting\n", s.svc.Name)
+		return
+	}
+
+	if time.Since(s.started) >= StableUptime {
+		s.exits = 0
+	}
+	s.exits++
+
+	if s.exits > s.retries {
+		s.state = "crash loop"
+		fmt.Printf("%s exited %d times in a row, restarts paused until the next change\n", s.svc.Name, s.exits)
+		fmt.Printf("last output of %s:\n%s", s.svc.Name, s.tail)
+		return
+	}
+
+	delay := DefaultBackoff << (s.exits - 1)
+	if delay > MaxBackoff || delay <= 0 {
+		delay = MaxBackoff
+	}
+	s.state = "restarting"
+	fmt.Printf("%s exited, restarting in %v\n", s.svc.Name, delay)
+	s.timer = time.AfterFunc(delay, func() {
+		s.Lock()
+		restarting := s.state == "restarting"
+		if restarting {
+			s.update()
+		}
+		s.Unlock()
+
+		if restarting {
+			s.notify()
+		}
+	})
+}
+
+// update restarts the service. Assumes it's called under a lock.
+func (s *supervisor) update() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.local() {
+		if s.proc != nil {
+			s.proc.stop()
+			s.proc = nil
+		}
+		if err := s.start(); err != nil {
+			fmt.Printf("failed to restart %s: %v\n", s.svc.Name, err)
+		}
+	} else if err := s.r.Update(s.svc); err != nil {
+		fmt.Printf("failed to restart %s: %v\n", s.svc.Name, err)
+	}
+	s.state = "running"
+	s.started = time.Now()
+	s.restarts++
+	printDebugger(s.svc.Name, s.builder)
+}
+
+// Restart rebuilds the service, if it's built by run, and restarts it. The
+// running instance is kept when the build fails. Paused restarts resume.
+func (s *supervisor) Restart() {
+	if s.stopped() {
+		return
+	}
+
+	// the service keeps running while it builds
+	var binary string
+	if s.builder != nil {
+		var err error
+		if binary, err = s.builder.Build(); err != nil {
+			fmt.Println(err)
+			fmt.Printf("build failed, keeping %s running\n", s.svc.Name)
+			return
+		}
+	}
+
+	s.Lock()
+	if s.state == "stopped" {
+		s.Unlock()
+		os.Remove(binary)
+		return
+	}
+	old := s.binary
+	if s.builder != nil {
+		s.binary = binary
+	}
+	s.exits = 0
+	s.update()
+	s.Unlock()
+
+	// other runtimes keep running the binary the service was created with
+	if s.local() && old != s.binary {
+		os.Remove(old)
+	}
+	s.notify()
+}
+
+// Stop stops supervising the service, and stops it.
+func (s *supervisor) Stop() {
+	s.Lock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.state = "stopped"
+	p := s.proc
+	s.proc = nil
+	s.Unlock()
+
+	if !s.local() {
+		s.r.Delete(s.svc)
+	} else if p != nil {
+		p.stop()
+	}
+	if len(s.binary) > 0 {
+		os.Remove(s.binary)
+	}
+}
+
+func (s *supervisor) stopped() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.state == "stopped"
+}
+
+// Service returns the service with its state, start time and restart count
+// in its metadata.
+func (s *supervisor) Service() *runtime.Service {
+	s.Lock()
+	defer s.Unlock()
+
+	return &runtime.Service{
+		Name:    s.svc.Name,
+		Version: s.svc.Version,
+		Source:  s.svc.Source,
+		Metadata: map[string]string{
+			"status":   s.state,
+			"started":  s.started.Format(time.RFC3339),
+			"restarts": strconv.Itoa(s.restarts),
+		},
+	}
+}
+
+func (s *supervisor) String() string {
+	s.Lock()
+	defer s.Unlock()
+
+	restarts := "restarts"
+	if s.restarts == 1 {
+		restarts = "restart"
+	}
+	return fmt.Sprintf("%s %s, %d %s", s.svc.Name, s.state, s.restarts, restarts)
+}
+
+// report prints the status line of the services, and records their state if
+// the run is detached.
+func report(sups []*supervisor) {
+	fmt.Println(statusLine(sups))
+	if detached() {
+		if err := writeState(sups); err != nil {
+			fmt.Println("ERROR", err)
+		}
+	}
+}
+
+// statusLine summarizes the state and restart count of every service.
+func statusLine(sups []*supervisor) string {
+	var parts []string
+	for _, s := range sups {
+		parts = append(parts, s.String())
+	}
+	return "status: " + strings.Join(parts, " | ")
+}
+
+// tail keeps the last lines written to it.
+type tail struct {
+	sync.Mutex
+
+	lines [][]byte
+	max   int
+	buf   []byte
+}
+
+func newTail(max int) *tail {
+	return &tail{max: max}
+}
+
+func (t *tail) Write(b []byte) (int, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.buf = append(t.buf, b...)
+	for {
+		i := bytes.IndexByte(t.buf, '\n')
+		if i < 0 {
+			break
+		}
+		t.lines = append(t.lines, append([]byte{}, t.buf[:i+1]...))
+		if len(t.lines) > t.max {
+			t.lines = t.lines[1:]
+		}
+		t.buf = t.buf[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// String returns the kept lines.
+func (t *tail) String() string {
+	t.Lock()
+	defer t.Unlock()
+	return string(bytes.Join(t.lines, nil))
+}
@@ -0,0 +1,172 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watcher watches the project directory and coalesces bursts of file
+// changes into a single callback.
+type watcher struct {
+	*fsnotify.Watcher
+
+	filter   *filter
+	debounce time.Duration
+	onChange func(files []string)
+}
+
+func newWatcher(f *filter, debounce time.Duration, onChange func([]string)) (*watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	return &watcher{
+		Watcher:  w,
+		filter:   f,
+		debounce: debounce,
+		onChange: onChange,
+	}, nil
+}
+
+// skipDir reports whether a directory is never watched. Hidden directories,
+// such as .git, and vendored dependencies are skipped.
+func skipDir(name string) bool {
+	return (strings.HasPrefix(name, ".") && name != ".") || name == "vendor" || name == "node_modules"
+}
+
+// AddTree recursively adds dir and its subdirectories to the watcher, skipping
+// hidden, vendored and ignored directories. Watching directories rather than
+// files reports changes to the files within them, including new files, with
+// a single watch per directory.
+func (w *watcher) AddTree(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// the directory may be removed while walking it
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir && (skipDir(info.Name()) || w.filter.Ignored(path, true)) {
+			return filepath.SkipDir
+		}
+
+		if err := w.Add(path); err != nil {
+			return watchError(path, err)
+		}
+		return nil
+	})
+}
+
+// watchError explains the errors returned when the operating system limits
+// on file watches are reached.
+func watchError(path string, err error) error {
+	switch {
+	case errors.Is(err, syscall.ENOSPC):
+		return fmt.Errorf("failed to watch %s: the inotify watch limit is reached, "+
+			"raise it with: sudo sysctl fs.inotify.max_user_watches=524288", path)
+	case errors.Is(err, syscall.EMFILE):
+		return fmt.Errorf("failed to watch %s: the open file limit is reached, "+
+			"raise it with: ulimit -n 4096", path)
+	}
+	return fmt.Errorf("failed to watch %s: %v", path, err)
+}
+
+// addCreated adds a newly created directory to the watcher, and returns the
+// files created within it before its watch was added.
+func (w *watcher) addCreated(dir string) []string {
+	if err := w.AddTree(dir); err != nil {
+		fmt.Println("ERROR", err)
+	}
+
+	var files []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != dir && (skipDir(info.Name()) || w.filter.Ignored(path, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files
+}
+
+// Run handles change events until the watcher is closed. Changed files are
+// collected until no change happened for the debounce window, after which
+// the callback is called once with all of them.
+func (w *watcher) Run() {
+	changed := make(map[string]bool)
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+
+			names := []string{filepath.Clean(event.Name)}
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				info, err := os.Stat(event.Name)
+				if err == nil && info.IsDir() {
+					if skipDir(info.Name()) || w.filter.Ignored(names[0], true) {
+						continue
+					}
+					names = w.addCreated(names[0])
+				}
+			}
+
+			for _, name := range names {
+				if w.filter.Accept(name) {
+					changed[name] = true
+					// drain a tick that fired but wasn't received yet, so
+					// the burst is reported once
+					if !timer.Stop() {
+						select {
+						case <-timer.C:
+						default:
+						}
+					}
+					timer.Reset(w.debounce)
+				}
+			}
+		case <-timer.C:
+			if len(changed) == 0 {
+				continue
+			}
+			var files []string
+			for file := range changed {
+				files = append(files, file)
+			}
+			sort.Strings(files)
+			changed = make(map[string]bool)
+			w.onChange(files)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			fmt.Println("ERROR", err)
+		}
+	}
+}
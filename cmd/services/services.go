@@ -1,39 +1,275 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path"
 	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/urfave/cli/v2"
 	mcli "github.com/go-micro/cli/cmd"
+	"go-micro.dev/v4/registry"
 )
 
+var flags []cli.Flag = []cli.Flag{
+	&cli.BoolFlag{
+		Name:  "watch",
+		Usage: "watch the registry and print service events as they happen",
+	},
+	&cli.StringFlag{
+		Name:  "format",
+		Value: "text",
+		Usage: "output format, e.g. text, wide or json",
+	},
+	&cli.StringSliceFlag{
+		Name:  "selector",
+		Usage: "only list nodes with matching metadata, e.g. --selector protocol=grpc",
+	},
+	&cli.StringFlag{
+		Name:  "version",
+		Usage: "only list services with this version",
+	},
+	&cli.StringFlag{
+		Name:  "sort",
+		Value: "name",
+		Usage: "sort services by name, version or nodes",
+	},
+}
+
 func init() {
 	mcli.Register(&cli.Command{
 		Name:   "services",
-		Usage:  "List services in the registry",
+		Usage:  "List services in the registry, e.g. " + mcli.App().Name + " services --format=wide 'hello*'",
+		Flags:  flags,
 		Action: List,
 	})
 }
 
-// List fetches running services from the registry and lists them. Exits on
+// List fetches running services from the registry and lists them. An optional
+// glob limits the listing to matching service names. Services are printed as
+// names, as a table or as JSON, depending on the format flag passed. Exits on
 // error.
 func List(ctx *cli.Context) error {
+	if ctx.Bool("watch") {
+		return Watch(ctx)
+	}
+
+	format := ctx.String("format")
+	if format != "text" && format != "wide" && format != "json" {
+		return fmt.Errorf("unknown format %q, must be one of text, wide or json", format)
+	}
+	by := ctx.String("sort")
+	if by != "name" && by != "version" && by != "nodes" {
+		return fmt.Errorf("unknown sort %q, must be one of name, version or nodes", by)
+	}
+
+	selector, err := parseSelector(ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	glob := ctx.Args().First()
+	if len(glob) > 0 {
+		if _, err := path.Match(glob, ""); err != nil {
+			return err
+		}
+	}
+
 	r := *mcli.DefaultOptions().Registry
 	srvs, err := r.ListServices()
 	if err != nil {
 		return err
 	}
 
-	var services []string
+	names := make(map[string]bool)
 	for _, srv := range srvs {
-		services = append(services, srv.Name)
+		if ok, _ := path.Match(glob, srv.Name); len(glob) > 0 && !ok {
+			continue
+		}
+		names[srv.Name] = true
 	}
 
-	sort.Strings(services)
-	for _, srv := range services {
-		fmt.Println(srv)
+	detailed := format != "text" || len(selector) > 0 || ctx.IsSet("version")
+	if !detailed {
+		var services []string
+		for name := range names {
+			services = append(services, name)
+		}
+
+		sort.Strings(services)
+		for _, srv := range services {
+			fmt.Println(srv)
+		}
+
+		return nil
+	}
+
+	var services []*registry.Service
+	for name := range names {
+		versions, err := r.GetService(name)
+		if err == registry.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, srv := range versions {
+			if ctx.IsSet("version") && srv.Version != ctx.String("version") {
+				continue
+			}
+			if srv = filterNodes(srv, selector); srv != nil {
+				services = append(services, srv)
+			}
+		}
+	}
+
+	sortServices(services, by)
+
+	switch format {
+	case "wide":
+		return printTable(services)
+	case "json":
+		b, err := json.MarshalIndent(services, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	default:
+		seen := make(map[string]bool)
+		for _, srv := range services {
+			if !seen[srv.Name] {
+				seen[srv.Name] = true
+				fmt.Println(srv.Name)
+			}
+		}
 	}
 
 	return nil
 }
+
+// parseSelector parses key=value pairs into a map.
+func parseSelector(pairs []string) (map[string]string, error) {
+	selector := make(map[string]string)
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("invalid selector %q, expected key=value", p)
+		}
+		selector[kv[0]] = kv[1]
+	}
+	return selector, nil
+}
+
+// filterNodes returns a copy of the service holding only the nodes whose
+// metadata matches the selector, or nil if no node matches.
+func filterNodes(srv *registry.Service, selector map[string]string) *registry.Service {
+	if len(selector) == 0 {
+		return srv
+	}
+
+	var nodes []*registry.Node
+	for _, node := range srv.Nodes {
+		match := true
+		for k, v := range selector {
+			if node.Metadata[k] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	cp := *srv
+	cp.Nodes = nodes
+	return &cp
+}
+
+func sortServices(services []*registry.Service, by string) {
+	sort.SliceStable(services, func(i, j int) bool {
+		a, b := services[i], services[j]
+		switch by {
+		case "version":
+			if a.Version != b.Version {
+				return a.Version < b.Version
+			}
+		case "nodes":
+			if len(a.Nodes) != len(b.Nodes) {
+				return len(a.Nodes) > len(b.Nodes)
+			}
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+}
+
+func printTable(services []*registry.Service) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tNODES\tADDRESSES\tMETADATA")
+	for _, srv := range services {
+		var addrs []string
+		for _, node := range srv.Nodes {
+			addrs = append(addrs, node.Address)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			srv.Name,
+			srv.Version,
+			strconv.Itoa(len(srv.Nodes)),
+			orNone(strings.Join(addrs, ",")),
+			orNone(formatMetadata(nodeMetadata(srv))),
+		)
+	}
+	return w.Flush()
+}
+
+// nodeMetadata merges the service metadata with the metadata shared by all
+// of its nodes.
+func nodeMetadata(srv *registry.Service) map[string]string {
+	md := make(map[string]string)
+	for k, v := range srv.Metadata {
+		md[k] = v
+	}
+	if len(srv.Nodes) == 0 {
+		return md
+	}
+
+	for k, v := range srv.Nodes[0].Metadata {
+		shared := true
+		for _, node := range srv.Nodes[1:] {
+			if node.Metadata[k] != v {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			md[k] = v
+		}
+	}
+	return md
+}
+
+func formatMetadata(md map[string]string) string {
+	var pairs []string
+	for k, v := range md {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func orNone(s string) string {
+	if len(s) == 0 {
+		return "<none>"
+	}
+	return s
+}
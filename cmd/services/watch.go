@@ -0,0 +1,142 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/registry"
+
+	mcli "github.com/go-micro/cli/cmd"
+)
+
+// event is a single node level registry event.
+type event struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Service string    `json:"service"`
+	Version string    `json:"version"`
+	Node    string    `json:"node,omitempty"`
+	Address string    `json:"address,omitempty"`
+}
+
+// Watch watches the registry and prints an event for every node of a service
+// that is created, updated or deleted, until interrupted. Events are limited
+// by the same glob, selector and version as the listing. Events are printed
+// as text or as newline delimited JSON, depending on the format flag passed.
+// Exits on error.
+func Watch(ctx *cli.Context) error {
+	format := ctx.String("format")
+	if format == "wide" {
+		return fmt.Errorf("--format=wide is not supported with --watch, use text or json")
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, must be one of text or json", format)
+	}
+
+	selector, err := parseSelector(ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	glob := ctx.Args().First()
+	if _, err := path.Match(glob, ""); err != nil {
+		return err
+	}
+
+	// a plain name is watched by the registry, a glob filtered here
+	var opts []registry.WatchOption
+	if len(glob) > 0 && !strings.ContainsAny(glob, "*?[\\") {
+		opts = append(opts, registry.WatchService(glob))
+	}
+
+	r := *mcli.DefaultOptions().Registry
+	w, err := r.Watch(opts...)
+	if err != nil {
+		return err
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	go func() {
+		<-sig
+		w.Stop()
+	}()
+
+	for {
+		res, err := w.Next()
+		if err == registry.ErrWatcherStopped {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if res.Service == nil {
+			continue
+		}
+		if ok, _ := path.Match(glob, res.Service.Name); len(glob) > 0 && !ok {
+			continue
+		}
+		if ctx.IsSet("version") && res.Service.Version != ctx.String("version") {
+			continue
+		}
+		srv := filterNodes(res.Service, selector)
+		if srv == nil {
+			continue
+		}
+
+		for _, e := range events(&registry.Result{Action: res.Action, Service: srv}) {
+			if err := printEvent(e, format); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// events flattens a watch result into one event per node.
+func events(res *registry.Result) []event {
+	now := time.Now()
+	srv := res.Service
+
+	if len(srv.Nodes) == 0 {
+		return []event{{
+			Time:    now,
+			Action:  res.Action,
+			Service: srv.Name,
+			Version: srv.Version,
+		}}
+	}
+
+	var evs []event
+	for _, node := range srv.Nodes {
+		evs = append(evs, event{
+			Time:    now,
+			Action:  res.Action,
+			Service: srv.Name,
+			Version: srv.Version,
+			Node:    node.Id,
+			Address: node.Address,
+		})
+	}
+	return evs
+}
+
+func printEvent(e event, format string) error {
+	if format == "json" {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	fmt.Printf("%s %-6s %s %s %s %s\n",
+		e.Time.Format("15:04:05"), e.Action, e.Service, e.Version, e.Node, e.Address)
+	return nil
+}
@@ -26,44 +26,66 @@ func Bidirectional(ctx *cli.Context) error {
 	srv.Init()
 	c := srv.Client()
 
+	l := newLimits(ctx)
+	sctx, cancel := l.context(ctx.Context)
+	defer cancel()
+
+	pacer, stop, err := l.pacer()
+	if err != nil {
+		return err
+	}
+	defer stop()
+
 	var r interface{}
 	request := c.NewRequest(service, endpoint, r, client.WithContentType("application/json"))
-	var rsp map[string]interface{}
-	stream, err := c.Stream(ctx.Context, request)
+
+	s := newSummary()
+	stream, err := c.Stream(sctx, request, l.callOptions()...)
 	if err != nil {
-		return err
+		return s.Done(err)
 	}
+	// closing the stream also ends a receive left waiting after a timeout
+	defer stream.Close()
+
+	for i, req := range requests {
+		if l.MaxMessages > 0 && s.received >= l.MaxMessages {
+			return s.Done(errMaxMessages)
+		}
 
-	for _, req := range requests {
 		d := json.NewDecoder(strings.NewReader(req))
 		d.UseNumber()
 
 		var creq map[string]interface{}
 		if err := d.Decode(&creq); err != nil {
-			return err
+			return s.Done(err)
+		}
+
+		if i > 0 {
+			if err := wait(sctx, pacer); err != nil {
+				return s.Done(err)
+			}
 		}
 
 		if err := stream.Send(creq); err != nil {
-			return err
+			return s.Done(err)
 		}
+		s.sent++
 
-		err := stream.Recv(&rsp)
+		rsp, err := recv(sctx, stream, l.IdleTimeout)
 		if err != nil {
-			return err
+			return s.Done(err)
 		}
+		s.received++
 
 		b, err := json.Marshal(rsp)
 		if err != nil {
-			return err
+			return s.Done(err)
 		}
 		fmt.Println(string(b))
 	}
 	if stream.Error() != nil {
-		return stream.Error()
-	}
-	if err := stream.Close(); err != nil {
-		return err
+		return s.Done(stream.Error())
 	}
 
-	return nil
+	return s.Done(stream.Close())
 }
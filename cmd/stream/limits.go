@@ -0,0 +1,150 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4/client"
+)
+
+var (
+	errMaxMessages = errors.New("max messages reached")
+	errIdleTimeout = errors.New("idle timeout")
+	errTimeout     = errors.New("timeout")
+)
+
+// limits bounds the lifetime of a stream.
+type limits struct {
+	// MaxMessages is the number of received messages after which the stream
+	// is closed.
+	MaxMessages int
+	// IdleTimeout is the maximum duration to wait for a single message.
+	IdleTimeout time.Duration
+	// Timeout is the maximum duration of the whole stream.
+	Timeout time.Duration
+	// Rate is the maximum number of requests sent per second.
+	Rate float64
+}
+
+func newLimits(ctx *cli.Context) limits {
+	return limits{
+		MaxMessages: ctx.Int("max-messages"),
+		IdleTimeout: ctx.Duration("idle-timeout"),
+		Timeout:     ctx.Duration("timeout"),
+		Rate:        ctx.Float64("rate"),
+	}
+}
+
+// context returns a context which is cancelled once the stream timeout
+// elapses.
+func (l limits) context(parent context.Context) (context.Context, context.CancelFunc) {
+	if l.Timeout > 0 {
+		return context.WithTimeout(parent, l.Timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// callOptions returns the client call options matching the limits.
+func (l limits) callOptions() []client.CallOption {
+	var opts []client.CallOption
+	if l.Timeout > 0 {
+		opts = append(opts, client.WithStreamTimeout(l.Timeout))
+	}
+	return opts
+}
+
+// pacer returns a channel which ticks at the configured rate, or nil if
+// requests are not paced. Rates above one request per nanosecond can't be
+// paced.
+func (l limits) pacer() (<-chan time.Time, func(), error) {
+	if l.Rate <= 0 {
+		return nil, func() {}, nil
+	}
+	interval := time.Duration(float64(time.Second) / l.Rate)
+	if interval <= 0 {
+		return nil, nil, fmt.Errorf("invalid rate %v, must be at most %d requests per second", l.Rate, time.Second)
+	}
+	t := time.NewTicker(interval)
+	return t.C, t.Stop, nil
+}
+
+// wait blocks until the next request may be sent according to the pacer.
+func wait(ctx context.Context, pacer <-chan time.Time) error {
+	if pacer == nil {
+		return nil
+	}
+	select {
+	case <-pacer:
+		return nil
+	case <-ctx.Done():
+		return errTimeout
+	}
+}
+
+// recv receives a single response from the stream. It gives up when the idle
+// timeout or the stream timeout elapses before a response arrives.
+func recv(ctx context.Context, stream client.Stream, idle time.Duration) (map[string]interface{}, error) {
+	type result struct {
+		rsp map[string]interface{}
+		err error
+	}
+
+	ch := make(chan result, 1)
+	go func() {
+		rsp := map[string]interface{}{}
+		err := stream.Recv(&rsp)
+		ch <- result{rsp, err}
+	}()
+
+	var idleC <-chan time.Time
+	if idle > 0 {
+		t := time.NewTimer(idle)
+		defer t.Stop()
+		idleC = t.C
+	}
+
+	select {
+	case r := <-ch:
+		return r.rsp, r.err
+	case <-idleC:
+		return nil, errIdleTimeout
+	case <-ctx.Done():
+		return nil, errTimeout
+	}
+}
+
+// summary keeps track of the messages passed over a stream.
+type summary struct {
+	sent     int
+	received int
+	start    time.Time
+}
+
+func newSummary() *summary {
+	return &summary{start: time.Now()}
+}
+
+// Print prints the message counts, the stream duration and the final status
+// to stderr, so it does not interfere with the responses printed to stdout.
+func (s *summary) Print(err error) {
+	status := "ok"
+	if err != nil {
+		status = err.Error()
+	}
+	fmt.Fprintf(os.Stderr, "sent %d, received %d messages in %v, status: %s\n",
+		s.sent, s.received, time.Since(s.start).Round(time.Millisecond), status)
+}
+
+// Done prints the summary and returns the error the command should exit
+// with. Reaching the message limit ends the stream successfully.
+func (s *summary) Done(err error) error {
+	s.Print(err)
+	if err == errMaxMessages {
+		return nil
+	}
+	return err
+}
@@ -1,7 +1,6 @@
 package stream
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -39,38 +38,43 @@ func Server(ctx *cli.Context) error {
 	srv.Init()
 	c := srv.Client()
 
+	l := newLimits(ctx)
+	sctx, cancel := l.context(ctx.Context)
+	defer cancel()
+
 	var r interface{}
 	request := c.NewRequest(service, endpoint, r, client.WithContentType("application/json"))
 
-	stream, err := c.Stream(context.Background(), request)
+	s := newSummary()
+	stream, err := c.Stream(sctx, request, l.callOptions()...)
 	if err != nil {
-		return err
+		return s.Done(err)
 	}
+	defer stream.Close()
+
 	if err := stream.Send(creq); err != nil {
-		return err
+		return s.Done(err)
 	}
+	s.sent++
 
 	for stream.Error() == nil {
-		rsp := &map[string]interface{}{}
-		err := stream.Recv(rsp)
+		if l.MaxMessages > 0 && s.received >= l.MaxMessages {
+			return s.Done(errMaxMessages)
+		}
+		rsp, err := recv(sctx, stream, l.IdleTimeout)
 		if err == io.EOF {
-			return nil
+			return s.Done(nil)
 		}
 		if err != nil {
-			return err
+			return s.Done(err)
 		}
+		s.received++
 		b, err := json.Marshal(rsp)
 		if err != nil {
-			return err
+			return s.Done(err)
 		}
 		fmt.Println(string(b))
 	}
-	if stream.Error() != nil {
-		return stream.Error()
-	}
-	if err := stream.Close(); err != nil {
-		return err
-	}
 
-	return nil
+	return s.Done(stream.Error())
 }
@@ -5,6 +5,28 @@ import (
 	mcli "github.com/go-micro/cli/cmd"
 )
 
+var flags []cli.Flag = []cli.Flag{
+	&cli.IntFlag{
+		Name:  "max-messages",
+		Usage: "stop after receiving this many messages, 0 means no limit",
+	},
+	&cli.DurationFlag{
+		Name:  "idle-timeout",
+		Usage: "stop when no message is received within this duration, e.g. 10s",
+	},
+	&cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "stop when the stream has been open for this duration, e.g. 1m",
+	},
+}
+
+var bidiFlags []cli.Flag = append([]cli.Flag{
+	&cli.Float64Flag{
+		Name:  "rate",
+		Usage: "maximum number of requests sent per second, 0 means no limit",
+	},
+}, flags...)
+
 func init() {
 	mcli.Register(&cli.Command{
 		Name:  "stream",
@@ -15,12 +37,14 @@ func init() {
 				Aliases: []string{"b"},
 				Usage:   "Create a bidirectional service stream, e.g. " + mcli.App().Name + " stream bidirectional helloworld Helloworld.PingPong '{\"stroke\": 1}' '{\"stroke\": 2}'",
 				Action:  Bidirectional,
+				Flags:   bidiFlags,
 			},
 			{
 				Name:    "server",
 				Aliases: []string{"s"},
 				Usage:   "Create a server service stream, e.g. " + mcli.App().Name + " stream server helloworld Helloworld.ServerStream '{\"count\": 10}'",
 				Action:  Server,
+				Flags:   flags,
 			},
 		},
 	})
@@ -0,0 +1,123 @@
+package top
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// render returns the lines of the current view.
+func (d *dashboard) render() []string {
+	d.Lock()
+	defer d.Unlock()
+
+	var lines []string
+	switch d.view {
+	case viewServices:
+		lines = d.renderServices()
+	case viewService:
+		lines = d.renderService()
+	case viewLogs:
+		lines = d.renderLogs()
+	}
+
+	if d.err != nil {
+		lines = append(lines, "", "error: "+d.err.Error())
+	}
+	return lines
+}
+
+func (d *dashboard) renderServices() []string {
+	rows := [][]string{{"NAME", "VERSIONS", "NODES"}}
+	for _, srv := range d.services {
+		rows = append(rows, []string{srv.Name, srv.Version, fmt.Sprint(len(srv.Nodes))})
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s services in %s registry at %s", fmt.Sprint(len(d.services)), d.reg.String(), time.Now().Format("15:04:05")),
+		"up/down select, enter show service, q quit",
+		"",
+	}
+	return append(lines, table(rows, d.selected)...)
+}
+
+func (d *dashboard) renderService() []string {
+	// Debug.Stats reports no CPU usage, so there's no CPU column
+	rows := [][]string{{"ID", "VERSION", "ADDRESS", "UPTIME", "MEMORY", "GOROUTINES", "GC", "REQUESTS", "ERRORS"}}
+	for _, n := range d.nodes {
+		if n.err != nil {
+			rows = append(rows, []string{n.Id, n.version, n.Address, "error: " + n.err.Error()})
+			continue
+		}
+		s := n.stats
+		rows = append(rows, []string{
+			n.Id,
+			n.version,
+			n.Address,
+			(time.Duration(s.Uptime) * time.Second).String(),
+			bytesize(s.Memory),
+			fmt.Sprint(s.Threads),
+			time.Duration(s.Gc).Round(time.Microsecond).String(),
+			fmt.Sprint(s.Requests),
+			fmt.Sprint(s.Errors),
+		})
+	}
+
+	lines := []string{
+		fmt.Sprintf("service %s, %d nodes at %s", d.service, len(d.nodes), time.Now().Format("15:04:05")),
+		"up/down select, enter or l tail logs, esc back, q quit",
+		"",
+	}
+	lines = append(lines, table(rows, d.node)...)
+	lines = append(lines, "", "ENDPOINTS")
+	for _, ep := range d.endpoints {
+		lines = append(lines, "  "+ep)
+	}
+	return lines
+}
+
+func (d *dashboard) renderLogs() []string {
+	lines := []string{
+		fmt.Sprintf("logs of service %s", d.service),
+		"esc back, q quit",
+		"",
+	}
+	return append(lines, d.logs...)
+}
+
+// table aligns the rows in columns and marks the selected row, which does
+// not count the header.
+func table(rows [][]string, selected int) []string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for i := range lines {
+		if i > 0 && i-1 == selected {
+			lines[i] = "> " + lines[i]
+			continue
+		}
+		lines[i] = "  " + lines[i]
+	}
+	return lines
+}
+
+// bytesize formats a number of bytes, e.g. 12.3MiB.
+func bytesize(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
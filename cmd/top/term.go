@@ -0,0 +1,135 @@
+package top
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// terminal puts the controlling terminal in raw mode, so single key presses
+// can be read, and restores it afterwards.
+type terminal struct {
+	state string
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+func newTerminal() (*terminal, error) {
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Mode()&os.ModeCharDevice == 0 {
+		return nil, fmt.Errorf("top requires an interactive terminal")
+	}
+
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal state: %v", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("failed to set terminal to raw mode: %v", err)
+	}
+
+	// switch to the alternate screen and hide the cursor
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+
+	return &terminal{state: state}, nil
+}
+
+// Restore leaves the alternate screen and restores the terminal state.
+func (t *terminal) Restore() {
+	fmt.Print("\x1b[?25h\x1b[?1049l")
+	stty(t.state)
+}
+
+// Size returns the number of rows and columns of the terminal.
+func (t *terminal) Size() (int, int) {
+	out, err := stty("size")
+	if err != nil {
+		return 24, 80
+	}
+	parts := strings.Fields(out)
+	if len(parts) != 2 {
+		return 24, 80
+	}
+	rows, err1 := strconv.Atoi(parts[0])
+	cols, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || rows == 0 || cols == 0 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+// Draw clears the screen and writes the lines, cut to the terminal size.
+func (t *terminal) Draw(lines []string) {
+	rows, cols := t.Size()
+	if len(lines) > rows {
+		lines = lines[:rows]
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	for i, line := range lines {
+		if r := []rune(line); len(r) > cols {
+			line = string(r[:cols])
+		}
+		b.WriteString(line)
+		if i < len(lines)-1 {
+			// raw mode does not translate newlines
+			b.WriteString("\r\n")
+		}
+	}
+	fmt.Print(b.String())
+}
+
+// Keys read from the terminal.
+const (
+	keyUp = iota + 1
+	keyDown
+	keyEnter
+	keyBack
+	keyQuit
+	keyLogs
+)
+
+// readKeys reads key presses from stdin and sends the ones the dashboard
+// handles to the channel.
+func readKeys(keys chan<- int) {
+	buf := make([]byte, 16)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+
+		in := string(buf[:n])
+		switch {
+		case in == "\x1b[A" || in == "k":
+			keys <- keyUp
+		case in == "\x1b[B" || in == "j":
+			keys <- keyDown
+		case in == "\r" || in == "\n":
+			keys <- keyEnter
+		case in == "\x1b" || in == "\x7f" || in == "h":
+			keys <- keyBack
+		case in == "q" || in == "\x03":
+			keys <- keyQuit
+		case in == "l":
+			keys <- keyLogs
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package top
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcli "github.com/go-micro/cli/cmd"
+	"github.com/urfave/cli/v2"
+	"go-micro.dev/v4"
+	"go-micro.dev/v4/client"
+	pb "go-micro.dev/v4/debug/proto"
+	"go-micro.dev/v4/registry"
+)
+
+// maxLogLines is the number of log lines kept for the log view.
+const maxLogLines = 500
+
+func init() {
+	mcli.Register(&cli.Command{
+		Name:   "top",
+		Usage:  "Show a live dashboard of the services in the registry and their nodes",
+		Action: Top,
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name:  "interval",
+				Value: 2 * time.Second,
+				Usage: "refresh interval of the dashboard",
+			},
+		},
+	})
+}
+
+type view int
+
+const (
+	viewServices view = iota
+	viewService
+	viewLogs
+)
+
+// node is a single service instance and its debug stats.
+type node struct {
+	version string
+	*registry.Node
+	stats *pb.StatsResponse
+	err   error
+}
+
+// dashboard holds the state of the dashboard between refreshes.
+type dashboard struct {
+	sync.Mutex
+
+	reg    registry.Registry
+	client client.Client
+
+	view     view
+	services []*registry.Service
+	selected int
+
+	service   string
+	nodes     []*node
+	endpoints []string
+	node      int
+
+	logs   []string
+	cancel context.CancelFunc
+
+	// refreshing is set while a refresh runs in the background, and stale
+	// when the view changed during it.
+	refreshing bool
+	stale      bool
+
+	err error
+}
+
+// Top renders a live dashboard of the services in the registry. Selecting a
+// service shows the debug stats of its nodes and its endpoints, and the logs
+// of a node can be tailed from there. Exits on error.
+func Top(ctx *cli.Context) error {
+	interval := ctx.Duration("interval")
+	if interval <= 0 {
+		return fmt.Errorf("invalid interval %v, must be greater than 0", interval)
+	}
+
+	srv := micro.NewService()
+	srv.Init()
+
+	d := &dashboard{
+		reg:    *mcli.DefaultOptions().Registry,
+		client: srv.Client(),
+	}
+
+	t, err := newTerminal()
+	if err != nil {
+		return err
+	}
+	defer t.Restore()
+
+	keys := make(chan int)
+	go readKeys(keys)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	redraw := make(chan bool, 1)
+	d.refresh()
+	t.Draw(d.render())
+
+	for {
+		select {
+		case k, ok := <-keys:
+			if !ok || k == keyQuit {
+				d.stopLogs()
+				return nil
+			}
+			if d.handle(k, redraw) {
+				d.update(redraw)
+			}
+		case <-ticker.C:
+			d.update(redraw)
+		case <-redraw:
+		}
+		t.Draw(d.render())
+	}
+}
+
+// handle updates the dashboard state for a key press and reports whether
+// the data of the new view must be fetched.
+func (d *dashboard) handle(k int, redraw chan bool) bool {
+	d.Lock()
+	defer d.Unlock()
+
+	switch d.view {
+	case viewServices:
+		switch k {
+		case keyUp:
+			d.selected = clamp(d.selected-1, len(d.services))
+		case keyDown:
+			d.selected = clamp(d.selected+1, len(d.services))
+		case keyEnter:
+			if len(d.services) > 0 {
+				d.view = viewService
+				d.service = d.services[d.selected].Name
+				d.node = 0
+				d.nodes = nil
+				d.endpoints = nil
+				return true
+			}
+		}
+	case viewService:
+		switch k {
+		case keyUp:
+			d.node = clamp(d.node-1, len(d.nodes))
+		case keyDown:
+			d.node = clamp(d.node+1, len(d.nodes))
+		case keyBack:
+			d.view = viewServices
+			return true
+		case keyLogs, keyEnter:
+			if len(d.nodes) > 0 {
+				d.view = viewLogs
+				d.startLogs(d.nodes[d.node].Node, redraw)
+			}
+		}
+	case viewLogs:
+		if k == keyBack {
+			d.view = viewService
+			d.stopLogsLocked()
+		}
+	}
+
+	return false
+}
+
+// update refreshes the current view in the background, so fetching stats
+// doesn't block navigation, and triggers a redraw when done. If the view
+// changes during a refresh, it's refreshed again afterwards.
+func (d *dashboard) update(redraw chan bool) {
+	d.Lock()
+	if d.refreshing {
+		d.stale = true
+		d.Unlock()
+		return
+	}
+	d.refreshing = true
+	d.Unlock()
+
+	go func() {
+		for {
+			d.refresh()
+			notify(redraw)
+
+			d.Lock()
+			again := d.stale
+			d.stale = false
+			d.refreshing = again
+			d.Unlock()
+			if !again {
+				return
+			}
+		}
+	}()
+}
+
+// refresh fetches the data shown in the current view. The data is dropped if
+// the view changed while it was fetched.
+func (d *dashboard) refresh() {
+	d.Lock()
+	v, name := d.view, d.service
+	d.Unlock()
+
+	switch v {
+	case viewServices:
+		srvs, err := d.listServices()
+		d.Lock()
+		if d.view == v {
+			d.services, d.err = srvs, err
+			d.selected = clamp(d.selected, len(srvs))
+		}
+		d.Unlock()
+	case viewService:
+		nodes, endpoints, err := d.getService(name)
+		d.Lock()
+		if d.view == v && d.service == name {
+			d.nodes, d.endpoints, d.err = nodes, endpoints, err
+			d.node = clamp(d.node, len(nodes))
+		}
+		d.Unlock()
+	}
+}
+
+// listServices returns every version of every service in the registry.
+func (d *dashboard) listServices() ([]*registry.Service, error) {
+	list, err := d.reg.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, srv := range list {
+		names[srv.Name] = true
+	}
+
+	var srvs []*registry.Service
+	for name := range names {
+		versions, err := d.reg.GetService(name)
+		if err != nil {
+			continue
+		}
+		nodes := &registry.Service{Name: name}
+		var vs []string
+		for _, v := range versions {
+			vs = append(vs, v.Version)
+			nodes.Nodes = append(nodes.Nodes, v.Nodes...)
+		}
+		sort.Strings(vs)
+		nodes.Version = strings.Join(vs, ",")
+		srvs = append(srvs, nodes)
+	}
+
+	sort.Slice(srvs, func(i, j int) bool {
+		return srvs[i].Name < srvs[j].Name
+	})
+	return srvs, nil
+}
+
+// getService returns the nodes of a service with their debug stats, and the
+// names of its endpoints.
+func (d *dashboard) getService(name string) ([]*node, []string, error) {
+	versions, err := d.reg.GetService(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nodes []*node
+	seen := make(map[string]bool)
+	var endpoints []string
+	for _, v := range versions {
+		for _, n := range v.Nodes {
+			nodes = append(nodes, &node{version: v.Version, Node: n})
+		}
+		for _, ep := range v.Endpoints {
+			if !seen[ep.Name] {
+				seen[ep.Name] = true
+				endpoints = append(endpoints, ep.Name)
+			}
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Id < nodes[j].Id
+	})
+	sort.Strings(endpoints)
+
+	debug := pb.NewDebugService(name, d.client)
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			n.stats, n.err = debug.Stats(ctx, &pb.StatsRequest{Service: name},
+				client.WithAddress(n.Address), client.WithRetries(0))
+		}(n)
+	}
+	wg.Wait()
+
+	return nodes, endpoints, nil
+}
+
+// startLogs tails the debug log of a node until stopped. Assumes it's called
+// under a lock.
+func (d *dashboard) startLogs(n *registry.Node, redraw chan bool) {
+	d.stopLogsLocked()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.logs = []string{fmt.Sprintf("tailing logs of %s at %s", n.Id, n.Address)}
+
+	name := d.service
+	debug := pb.NewDebugService(name, d.client)
+	go func() {
+		stream, err := debug.Log(ctx, &pb.LogRequest{Service: name, Stream: true, Count: 50},
+			client.WithAddress(n.Address), client.WithRetries(0))
+		if err != nil {
+			d.appendLog("error: " + err.Error())
+			notify(redraw)
+			return
+		}
+		defer stream.Close()
+
+		for {
+			rec, err := stream.Recv()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				d.appendLog("error: " + err.Error())
+				notify(redraw)
+				return
+			}
+			d.appendLog(formatRecord(rec))
+			notify(redraw)
+		}
+	}()
+}
+
+func (d *dashboard) appendLog(line string) {
+	d.Lock()
+	defer d.Unlock()
+	d.logs = append(d.logs, line)
+	if len(d.logs) > maxLogLines {
+		d.logs = d.logs[len(d.logs)-maxLogLines:]
+	}
+}
+
+func (d *dashboard) stopLogs() {
+	d.Lock()
+	defer d.Unlock()
+	d.stopLogsLocked()
+}
+
+func (d *dashboard) stopLogsLocked() {
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+}
+
+// clamp limits an index to a list of n items.
+func clamp(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+// notify triggers a redraw without blocking.
+func notify(redraw chan bool) {
+	select {
+	case redraw <- true:
+	default:
+	}
+}
+
+func formatRecord(rec *pb.Record) string {
+	var md []string
+	for k, v := range rec.Metadata {
+		md = append(md, k+"="+v)
+	}
+	sort.Strings(md)
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s",
+		time.Unix(rec.Timestamp, 0).Format("2006-01-02 15:04:05"),
+		strings.Join(md, " "),
+		rec.Message,
+	))
+}
@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// edit is a line of an edit script: kept (' '), removed ('-') or added ('+').
+type edit struct {
+	op   byte
+	line string
+}
+
+// unifiedDiff returns the unified diff of a file changing from a to b. A file
+// that doesn't exist yet is diffed against /dev/null.
+func unifiedDiff(path string, a, b []byte, exists bool) string {
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	if exists {
+		fmt.Fprintf(&sb, "--- a/%s\n", path)
+	} else {
+		sb.WriteString("--- /dev/null\n")
+	}
+	fmt.Fprintf(&sb, "+++ b/%s\n", path)
+
+	// the number of lines of a and b before each edit
+	as := make([]int, len(edits)+1)
+	bs := make([]int, len(edits)+1)
+	for i, e := range edits {
+		as[i+1], bs[i+1] = as[i], bs[i]
+		if e.op != '+' {
+			as[i+1]++
+		}
+		if e.op != '-' {
+			bs[i+1]++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+
+		// extend the hunk with the next change while their contexts overlap
+		// or touch
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(edits) && j <= end+2*diffContext+1; j++ {
+			if edits[j].op != ' ' {
+				end = j
+			}
+		}
+		i = end + 1
+		end += diffContext
+		if end >= len(edits) {
+			end = len(edits) - 1
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(as[start], as[end+1]-as[start]),
+			hunkRange(bs[start], bs[end+1]-bs[start]))
+		for _, e := range edits[start : end+1] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.line)
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats the range of lines of a hunk. An empty range starts at
+// the line before it.
+func hunkRange(before, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if n == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, n)
+}
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+// diffLines returns the shortest edit script from a to b, using their longest
+// common subsequence. Generated files are small, so the quadratic table is
+// fine.
+func diffLines(a, b []string) []edit {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	return edits
+}
@@ -1,6 +1,10 @@
 package generator
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,6 +21,8 @@ type Generator interface {
 
 type generator struct {
 	opts Options
+	// in reads the answers to conflict prompts.
+	in *bufio.Reader
 }
 
 // File represents a file to generate.
@@ -27,65 +33,213 @@ type File struct {
 	Template string
 }
 
-// Generate generates project template files.
+// What happens to a generated file.
+const (
+	statusCreate    = "create"
+	statusOverwrite = "overwrite"
+	statusUnchanged = "unchanged"
+	statusSkip      = "skip"
+	statusNew       = "new"
+	statusConflict  = "conflict"
+)
+
+// funcMap are the functions available in templates, including those of a
+// template pack.
+var funcMap = template.FuncMap{
+	"dehyphen": func(s string) string {
+		return strings.ReplaceAll(s, "-", "")
+	},
+	"lowerhyphen": func(s string) string {
+		return strings.ReplaceAll(s, "-", "_")
+	},
+	"tohyphen": func(s string) string {
+		return strings.ReplaceAll(s, "_", "-")
+	},
+	"gitorg": func(s string) string {
+		list := strings.Split(s, "/")
+		return strings.Join(list[:2], "/")
+	},
+	"lower": strings.ToLower,
+	"title": func(s string) string {
+		t := strings.ReplaceAll(strings.Title(s), "-", "")
+		return strings.ReplaceAll(t, "_", "")
+	},
+}
+
+// Generate generates project template files. Templates in the template pack
+// override the built-in template of the file with the same path, and the
+// other files of the pack are added. Added files are only created if they
+// don't exist yet, whatever the conflict strategy.
 func (g *generator) Generate(files []File) error {
-	for _, file := range files {
-		fp := filepath.Join(g.opts.Directory, file.Path)
-		dir := filepath.Dir(fp)
+	p, err := readPack(g.opts.TemplateDir, g.opts)
+	if err != nil {
+		return err
+	}
 
-		if file.Template == "" {
-			dir = fp
-		}
+	added, err := p.added(files, g.opts)
+	if err != nil {
+		return err
+	}
+	n := len(files)
+	files = append(files[:n:n], added...)
 
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return err
-			}
+	for i, file := range files {
+		fp := filepath.Join(g.opts.Directory, file.Path)
+		name := fp
+		if pf, ok := p[file.Path]; ok && file.Template != "" {
+			name = pf.source
+			file.Template = pf.template
 		}
 
 		if file.Template == "" {
+			if err := g.mkdir(fp); err != nil {
+				return err
+			}
 			continue
 		}
 
-		f, err := os.Create(fp)
+		t, err := template.New(name).Funcs(funcMap).Parse(file.Template)
 		if err != nil {
 			return err
 		}
 
-		fn := template.FuncMap{
-			"dehyphen": func(s string) string {
-				return strings.ReplaceAll(s, "-", "")
-			},
-			"lowerhyphen": func(s string) string {
-				return strings.ReplaceAll(s, "-", "_")
-			},
-			"tohyphen": func(s string) string {
-				return strings.ReplaceAll(s, "_", "-")
-			},
-			"gitorg": func(s string) string {
-				list := strings.Split(s, "/")
-				return strings.Join(list[:2], "/")
-			},
-			"lower": strings.ToLower,
-			"title": func(s string) string {
-				t := strings.ReplaceAll(strings.Title(s), "-", "")
-				return strings.ReplaceAll(t, "_", "")
-			},
+		var b bytes.Buffer
+		if err := t.Execute(&b, g.opts); err != nil {
+			return err
+		}
+
+		if err := g.write(fp, b.Bytes(), i >= n); err != nil {
+			return err
 		}
-		t, err := template.New(fp).Funcs(fn).Parse(file.Template)
-		if err != nil {
+	}
+
+	return nil
+}
+
+// mkdir creates a directory, or reports creating it in a dry run.
+func (g *generator) mkdir(dir string) error {
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return err
+	}
+	if !g.opts.DryRun && !g.opts.Diff {
+		if err := os.MkdirAll(dir, 0755); err != nil {
 			return err
 		}
+	}
+	if g.opts.DryRun || len(g.opts.Conflict) > 0 {
+		fmt.Printf("%-9s %s\n", statusCreate, dir+string(filepath.Separator))
+	}
+	return nil
+}
 
-		err = t.Execute(f, g.opts)
+// write writes a generated file, resolving a conflict with an existing file
+// that changed according to the conflict strategy, or keeping it if keep is
+// set. In a dry run it reports what would happen to the file instead, or
+// prints the diff of the changes.
+func (g *generator) write(path string, b []byte, keep bool) error {
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	exists := err == nil
+	changed := !exists || !bytes.Equal(old, b)
+
+	if g.opts.Diff {
+		if !exists || changed && !keep {
+			fmt.Print(unifiedDiff(filepath.ToSlash(path), old, b, exists))
+		}
+		return nil
+	}
+
+	status := statusCreate
+	switch {
+	case exists && !changed:
+		status = statusUnchanged
+	case exists && keep:
+		status = statusSkip
+	case exists:
+		status, err = g.resolve(path, old, b)
 		if err != nil {
 			return err
 		}
 	}
 
+	if !g.opts.DryRun {
+		switch status {
+		case statusCreate, statusOverwrite:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, b, 0644); err != nil {
+				return err
+			}
+		case statusNew:
+			if err := os.WriteFile(path+".new", b, 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	if g.opts.DryRun || len(g.opts.Conflict) > 0 {
+		if status == statusNew {
+			path += ".new"
+		}
+		fmt.Printf("%-9s %s\n", status, path)
+	}
 	return nil
 }
 
+// resolve returns what to do with an existing file that changed, according
+// to the conflict strategy.
+func (g *generator) resolve(path string, old, b []byte) (string, error) {
+	switch g.opts.Conflict {
+	case ConflictSkip:
+		return statusSkip, nil
+	case ConflictNew:
+		return statusNew, nil
+	case ConflictPrompt:
+		if g.opts.DryRun {
+			return statusConflict, nil
+		}
+		ok, err := g.ask(path, old, b)
+		if err != nil || !ok {
+			return statusSkip, err
+		}
+	}
+	return statusOverwrite, nil
+}
+
+// ask asks whether to overwrite a file that changed, and prints the diff of
+// the changes on request.
+func (g *generator) ask(path string, old, b []byte) (bool, error) {
+	if g.in == nil {
+		g.in = bufio.NewReader(os.Stdin)
+	}
+
+	for {
+		fmt.Printf("overwrite %s? [y,n,d] ", path)
+		line, err := g.in.ReadString('\n')
+		if err == io.EOF && len(line) == 0 {
+			fmt.Println()
+			return false, fmt.Errorf("no answer to overwrite %s", path)
+		}
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		case "d", "diff":
+			fmt.Print(unifiedDiff(filepath.ToSlash(path), old, b, true))
+		default:
+			fmt.Println("y overwrites the file, n skips it and d prints the diff of the changes")
+		}
+	}
+}
+
 func (g *generator) Options() Options {
 	return g.opts
 }
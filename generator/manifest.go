@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ManifestFile is the name of the project manifest, which records the options
+// a project was generated with.
+const ManifestFile = ".go-micro.yaml"
+
+const manifestHeader = `# Options this project was generated with by go-micro new. The go-micro
+# generate commands read them, and update them with the flags passed.
+`
+
+// ReadManifest reads the options recorded in the project manifest in dir. The
+// directory of the returned options is set to dir.
+func ReadManifest(dir string) (Options, error) {
+	var opts Options
+
+	b, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return opts, err
+	}
+	if err := yaml.Unmarshal(b, &opts); err != nil {
+		return opts, err
+	}
+
+	opts.Directory = dir
+	return opts, nil
+}
+
+// WriteManifest records the options in the project manifest in their
+// directory. In a dry run it's reported like the generated files.
+func WriteManifest(opts Options) error {
+	b, err := yaml.Marshal(opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(manifestHeader)
+	buf.Write(b)
+
+	// the manifest is meant to be updated, whatever the conflict strategy
+	if len(opts.Conflict) > 0 {
+		opts.Conflict = ConflictOverwrite
+	}
+
+	g := &generator{opts: opts}
+	return g.write(filepath.Join(opts.Directory, ManifestFile), buf.Bytes(), false)
+}
+
+// WithOptions replaces all options, e.g. with the options read from a project
+// manifest. Options passed after it override them.
+func WithOptions(opts Options) Option {
+	return func(o *Options) {
+		*o = opts
+	}
+}
@@ -7,8 +7,24 @@ type Options struct {
 	Service string
 	// Vendor is the service vendor.
 	Vendor string
-	// Directory is the directory where the files will be generated to.
-	Directory string
+	// Directory is the directory where the files will be generated to. It's
+	// not recorded in the project manifest.
+	Directory string `yaml:"-"`
+	// TemplateDir is the directory of a template pack overriding or adding
+	// to the built-in templates. It's not recorded in the project manifest.
+	TemplateDir string `yaml:"-"`
+	// DryRun reports what generating the files would do instead of writing
+	// them. It's not recorded in the project manifest.
+	DryRun bool `yaml:"-"`
+	// Diff prints a unified diff of the changes generating the files would
+	// make instead of writing them. It's not recorded in the project
+	// manifest.
+	Diff bool `yaml:"-"`
+	// Conflict is the strategy for existing files that changed, one of
+	// ConflictSkip, ConflictOverwrite, ConflictPrompt or ConflictNew. If set,
+	// what happened to each file is reported. If empty, files are
+	// overwritten silently. It's not recorded in the project manifest.
+	Conflict string `yaml:"-"`
 
 	// Client determines whether or not the project is a client project.
 	Client bool
@@ -40,6 +56,19 @@ type Options struct {
 	PostgresAddress string
 }
 
+// Conflict strategies for existing files that changed.
+const (
+	// ConflictSkip keeps the existing file.
+	ConflictSkip = "skip"
+	// ConflictOverwrite overwrites the existing file.
+	ConflictOverwrite = "overwrite"
+	// ConflictPrompt asks whether to overwrite the existing file.
+	ConflictPrompt = "prompt"
+	// ConflictNew keeps the existing file, and writes the generated file next
+	// to it with a .new extension.
+	ConflictNew = "new"
+)
+
 // Option manipulates the Options passed.
 type Option func(o *Options)
 
@@ -64,6 +93,36 @@ func Directory(d string) Option {
 	}
 }
 
+// TemplateDir sets the directory of the template pack.
+func TemplateDir(d string) Option {
+	return func(o *Options) {
+		o.TemplateDir = d
+	}
+}
+
+// DryRun sets whether to report what generating files would do instead of
+// writing them.
+func DryRun(d bool) Option {
+	return func(o *Options) {
+		o.DryRun = d
+	}
+}
+
+// Diff sets whether to print a diff of the changes generating files would make
+// instead of writing them.
+func Diff(d bool) Option {
+	return func(o *Options) {
+		o.Diff = d
+	}
+}
+
+// Conflict sets the strategy for existing files that changed.
+func Conflict(c string) Option {
+	return func(o *Options) {
+		o.Conflict = c
+	}
+}
+
 // Client sets whether or not the project is a client project.
 func Client(c bool) Option {
 	return func(o *Options) {
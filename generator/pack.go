@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// PackExt is the optional extension of the files in a template pack. It's
+// trimmed from the path of the file generated, so templates of Go files can be
+// named e.g. main.go.tmpl and aren't compiled as part of the pack.
+const PackExt = ".tmpl"
+
+// pack is a template pack, a directory of templates keyed by the path of the
+// file they generate.
+type pack map[string]packFile
+
+type packFile struct {
+	// source is the path of the template in the pack.
+	source string
+	// template is the contents of the template.
+	template string
+}
+
+// readPack reads the template pack in dir. The paths of its files are
+// templates too, executed with the options, so e.g. handler/{{.Service}}.go
+// overrides the handler of the service. An empty dir is an empty pack.
+func readPack(dir string, opts Options) (pack, error) {
+	p := make(pack)
+	if len(dir) == 0 {
+		return p, nil
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		t, err := template.New(path).Funcs(funcMap).Parse(filepath.ToSlash(rel))
+		if err != nil {
+			return fmt.Errorf("invalid template pack path: %v", err)
+		}
+		var name bytes.Buffer
+		if err := t.Execute(&name, opts); err != nil {
+			return fmt.Errorf("invalid template pack path: %v", err)
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		p[strings.TrimSuffix(name.String(), PackExt)] = packFile{
+			source:   path,
+			template: string(b),
+		}
+		return nil
+	})
+	return p, err
+}
+
+// builtinPaths are the paths of all the files the built-in templates
+// generate, whatever the project type and features. They're templates,
+// executed with the options.
+var builtinPaths = []string{
+	".dockerignore",
+	".gitignore",
+	"Dockerfile",
+	"Makefile",
+	"Tiltfile",
+	"go.mod",
+	"main.go",
+	"plugins.go",
+	"skaffold.yaml",
+	"handler/{{.Service}}.go",
+	"handler/health.go",
+	"proto/{{.Service}}.proto",
+	"proto/health.proto",
+	"postgres/postgres.go",
+	"postgres/sqlc.yaml",
+	"postgres/migrations/001_create_schema.sql",
+	"postgres/queries/example.sql",
+	"resources/clusterrole.yaml",
+	"resources/configmap.yaml",
+	"resources/deployment.yaml",
+	"resources/rolebinding.yaml",
+	"resources/base/app.env",
+	"resources/base/clusterrole.yaml",
+	"resources/base/deployment.yaml",
+	"resources/base/kustomization.yaml",
+	"resources/base/rolebinding.yaml",
+	"resources/dev/kustomization.yaml",
+	"resources/prod/kustomization.yaml",
+}
+
+// added returns the files of the pack that no built-in template generates,
+// sorted by path. Files overriding a built-in template the project doesn't
+// use, e.g. skaffold.yaml without skaffold, aren't added.
+func (p pack) added(files []File, opts Options) ([]File, error) {
+	known := make(map[string]bool)
+	for _, file := range files {
+		known[file.Path] = true
+	}
+	for _, path := range builtinPaths {
+		t, err := template.New(path).Funcs(funcMap).Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		var name bytes.Buffer
+		if err := t.Execute(&name, opts); err != nil {
+			return nil, err
+		}
+		known[name.String()] = true
+	}
+
+	var paths []string
+	for path := range p {
+		if !known[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var added []File
+	for _, path := range paths {
+		added = append(added, File{Path: path, Template: p[path].template})
+	}
+	return added, nil
+}
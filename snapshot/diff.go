@@ -0,0 +1,338 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-micro.dev/v4/registry"
+)
+
+// ChangeType is the type of a change between two snapshots.
+type ChangeType int
+
+const (
+	// Added is a resource that only exists in the new snapshot.
+	Added ChangeType = iota
+	// Removed is a resource that only exists in the old snapshot.
+	Removed
+	// Modified is a resource that exists in both snapshots, but differs.
+	Modified
+)
+
+// String returns the diff symbol of the change type.
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// Kinds of resources a change applies to.
+const (
+	KindService  = "service"
+	KindVersion  = "version"
+	KindNode     = "node"
+	KindEndpoint = "endpoint"
+	KindField    = "field"
+)
+
+// Change is a single difference between two snapshots.
+type Change struct {
+	// Type is whether the resource was added, removed or modified.
+	Type ChangeType
+	// Kind is the kind of resource that changed.
+	Kind string
+	// Service is the name of the service the change applies to.
+	Service string
+	// Version is the version of the service the change applies to.
+	Version string
+	// Endpoint is the endpoint an endpoint or field change applies to.
+	Endpoint string
+	// Name is the node id or field path that changed.
+	Name string
+	// From is the old value of a modified resource.
+	From string
+	// To is the new value of a modified resource.
+	To string
+}
+
+// String formats the change as a single line, e.g.
+//
+//	~ field helloworld latest Helloworld.Call request.name: string -> int64
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s", c.Type, c.Kind, c.Service)
+	if len(c.Version) > 0 && c.Kind != KindVersion {
+		s += " " + c.Version
+	}
+	if len(c.Endpoint) > 0 {
+		s += " " + c.Endpoint
+	}
+	if len(c.Name) > 0 {
+		s += " " + c.Name
+	}
+	if c.Type == Modified {
+		s += fmt.Sprintf(": %s -> %s", c.From, c.To)
+	} else if len(c.From+c.To) > 0 {
+		s += fmt.Sprintf(" (%s%s)", c.From, c.To)
+	}
+	return s
+}
+
+// Breaking reports whether the change breaks clients of the service, which is
+// the case for removed endpoints and for removed fields or fields that changed
+// type. Added endpoints and fields are compatible.
+func (c Change) Breaking() bool {
+	switch c.Kind {
+	case KindEndpoint, KindField:
+		return c.Type != Added
+	}
+	return false
+}
+
+// Diff compares two snapshots and returns the services, versions, nodes,
+// endpoints and fields that were added, removed or modified. When a service
+// had a single version replaced by another, the nodes and endpoints of both
+// versions are compared.
+func Diff(a, b *Snapshot) []Change {
+	var changes []Change
+
+	names := make(map[string]bool)
+	for _, srv := range a.Services {
+		names[srv.Name] = true
+	}
+	for _, srv := range b.Services {
+		names[srv.Name] = true
+	}
+	var sorted []string
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		changes = append(changes, DiffService(name, a.Get(name), b.Get(name))...)
+	}
+
+	return changes
+}
+
+// DiffService compares the old and new versions of a service.
+func DiffService(name string, a, b []*registry.Service) []Change {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	if len(a) == 0 {
+		return []Change{{Type: Added, Kind: KindService, Service: name}}
+	}
+	if len(b) == 0 {
+		return []Change{{Type: Removed, Kind: KindService, Service: name}}
+	}
+
+	var changes []Change
+	var removed, added []*registry.Service
+
+	for _, old := range a {
+		if srv := version(b, old.Version); srv != nil {
+			changes = append(changes, DiffVersion(old, srv)...)
+		} else {
+			removed = append(removed, old)
+		}
+	}
+	for _, srv := range b {
+		if version(a, srv.Version) == nil {
+			added = append(added, srv)
+		}
+	}
+
+	if len(removed) == 1 && len(added) == 1 {
+		changes = append(changes, Change{
+			Type:    Modified,
+			Kind:    KindVersion,
+			Service: name,
+			From:    removed[0].Version,
+			To:      added[0].Version,
+		})
+		return append(changes, DiffVersion(removed[0], added[0])...)
+	}
+
+	for _, srv := range removed {
+		changes = append(changes, Change{Type: Removed, Kind: KindVersion, Service: name, Name: srv.Version})
+	}
+	for _, srv := range added {
+		changes = append(changes, Change{Type: Added, Kind: KindVersion, Service: name, Name: srv.Version})
+	}
+
+	return changes
+}
+
+// DiffVersion compares the nodes and endpoints of a single service version.
+func DiffVersion(a, b *registry.Service) []Change {
+	var changes []Change
+
+	for _, old := range a.Nodes {
+		node := findNode(b.Nodes, old.Id)
+		if node == nil {
+			changes = append(changes, Change{
+				Type:    Removed,
+				Kind:    KindNode,
+				Service: b.Name,
+				Version: b.Version,
+				Name:    old.Id,
+				From:    old.Address,
+			})
+			continue
+		}
+		if node.Address != old.Address {
+			changes = append(changes, Change{
+				Type:    Modified,
+				Kind:    KindNode,
+				Service: b.Name,
+				Version: b.Version,
+				Name:    old.Id,
+				From:    old.Address,
+				To:      node.Address,
+			})
+		}
+	}
+	for _, node := range b.Nodes {
+		if findNode(a.Nodes, node.Id) == nil {
+			changes = append(changes, Change{
+				Type:    Added,
+				Kind:    KindNode,
+				Service: b.Name,
+				Version: b.Version,
+				Name:    node.Id,
+				To:      node.Address,
+			})
+		}
+	}
+
+	return append(changes, DiffEndpoints(a, b)...)
+}
+
+// DiffEndpoints compares the endpoints of two services, including the
+// fields of their request and response types.
+func DiffEndpoints(a, b *registry.Service) []Change {
+	var changes []Change
+
+	for _, old := range a.Endpoints {
+		ep := findEndpoint(b.Endpoints, old.Name)
+		if ep == nil {
+			changes = append(changes, Change{
+				Type:     Removed,
+				Kind:     KindEndpoint,
+				Service:  b.Name,
+				Version:  b.Version,
+				Endpoint: old.Name,
+			})
+			continue
+		}
+
+		for _, fc := range DiffValue("request", old.Request, ep.Request) {
+			fc.Service, fc.Version, fc.Endpoint = b.Name, b.Version, ep.Name
+			changes = append(changes, fc)
+		}
+		for _, fc := range DiffValue("response", old.Response, ep.Response) {
+			fc.Service, fc.Version, fc.Endpoint = b.Name, b.Version, ep.Name
+			changes = append(changes, fc)
+		}
+	}
+	for _, ep := range b.Endpoints {
+		if findEndpoint(a.Endpoints, ep.Name) == nil {
+			changes = append(changes, Change{
+				Type:     Added,
+				Kind:     KindEndpoint,
+				Service:  b.Name,
+				Version:  b.Version,
+				Endpoint: ep.Name,
+			})
+		}
+	}
+
+	return changes
+}
+
+// DiffValue compares two values field by field and returns the fields that
+// were added, removed or changed type. Messages with fields are compared by
+// their fields, not their type name. Paths are rooted at path, e.g.
+// request.name.
+func DiffValue(path string, a, b *registry.Value) []Change {
+	switch {
+	case a == nil && b == nil:
+		return nil
+	case a == nil:
+		return []Change{{Type: Added, Kind: KindField, Name: path, To: b.Type}}
+	case b == nil:
+		return []Change{{Type: Removed, Kind: KindField, Name: path, From: a.Type}}
+	}
+
+	// the type names of messages with fields, e.g. CallRequest, don't matter
+	// on the wire, so renamed messages are compared by their fields only
+	var changes []Change
+	structs := len(a.Values) > 0 && len(b.Values) > 0
+	if a.Type != b.Type && (!structs || repeated(a.Type) != repeated(b.Type)) {
+		changes = append(changes, Change{
+			Type: Modified,
+			Kind: KindField,
+			Name: path,
+			From: a.Type,
+			To:   b.Type,
+		})
+	}
+
+	for _, old := range a.Values {
+		changes = append(changes, DiffValue(path+"."+old.Name, old, findValue(b.Values, old.Name))...)
+	}
+	for _, v := range b.Values {
+		if findValue(a.Values, v.Name) == nil {
+			changes = append(changes, DiffValue(path+"."+v.Name, nil, v)...)
+		}
+	}
+
+	return changes
+}
+
+func repeated(typ string) bool {
+	return strings.HasPrefix(typ, "[]")
+}
+
+func version(srvs []*registry.Service, v string) *registry.Service {
+	for _, srv := range srvs {
+		if srv.Version == v {
+			return srv
+		}
+	}
+	return nil
+}
+
+func findNode(nodes []*registry.Node, id string) *registry.Node {
+	for _, node := range nodes {
+		if node.Id == id {
+			return node
+		}
+	}
+	return nil
+}
+
+func findEndpoint(eps []*registry.Endpoint, name string) *registry.Endpoint {
+	for _, ep := range eps {
+		if ep.Name == name {
+			return ep
+		}
+	}
+	return nil
+}
+
+func findValue(vals []*registry.Value, name string) *registry.Value {
+	for _, v := range vals {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
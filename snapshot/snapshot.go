@@ -0,0 +1,129 @@
+// Package snapshot captures, stores and compares the contents of a registry.
+package snapshot
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go-micro.dev/v4/registry"
+	"gopkg.in/yaml.v2"
+)
+
+// Snapshot represents the services registered in a registry at a point in
+// time.
+type Snapshot struct {
+	// Registry is the name of the registry the snapshot was taken from.
+	Registry string `json:"registry" yaml:"registry"`
+	// Created is the time the snapshot was taken.
+	Created time.Time `json:"created" yaml:"created"`
+	// Services holds every version of every registered service.
+	Services []*registry.Service `json:"services" yaml:"services"`
+}
+
+// Take lists all services in the registry and fetches their versions,
+// endpoints and nodes.
+func Take(r registry.Registry) (*Snapshot, error) {
+	srvs, err := r.ListServices()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	s := &Snapshot{
+		Registry: r.String(),
+		Created:  time.Now(),
+	}
+	for _, srv := range srvs {
+		if seen[srv.Name] {
+			continue
+		}
+		seen[srv.Name] = true
+
+		versions, err := r.GetService(srv.Name)
+		if err == registry.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.Services = append(s.Services, versions...)
+	}
+
+	s.Sort()
+	return s, nil
+}
+
+// Sort orders the services by name and version, and their endpoints and
+// nodes by name and id, so snapshots are stable.
+func (s *Snapshot) Sort() {
+	sort.Slice(s.Services, func(i, j int) bool {
+		a, b := s.Services[i], s.Services[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	for _, srv := range s.Services {
+		sort.Slice(srv.Endpoints, func(i, j int) bool {
+			return srv.Endpoints[i].Name < srv.Endpoints[j].Name
+		})
+		sort.Slice(srv.Nodes, func(i, j int) bool {
+			return srv.Nodes[i].Id < srv.Nodes[j].Id
+		})
+	}
+}
+
+// Get returns the versions of a service in the snapshot.
+func (s *Snapshot) Get(name string) []*registry.Service {
+	var srvs []*registry.Service
+	for _, srv := range s.Services {
+		if srv.Name == name {
+			srvs = append(srvs, srv)
+		}
+	}
+	return srvs
+}
+
+// Write writes the snapshot to w in either JSON or YAML.
+func (s *Snapshot) Write(w io.Writer, format string) error {
+	var b []byte
+	var err error
+	if format == "json" {
+		b, err = json.MarshalIndent(s, "", "  ")
+		b = append(b, '\n')
+	} else {
+		b, err = yaml.Marshal(s)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// Read reads a snapshot from a JSON or YAML file, depending on its
+// extension.
+func Read(path string) (*Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(Snapshot)
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(b, s)
+	} else {
+		err = yaml.Unmarshal(b, s)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.Sort()
+	return s, nil
+}